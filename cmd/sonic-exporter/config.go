@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/mwennrich/sonic-exporter/internal/collector"
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+)
+
+// fileConfig is the optional config file of the exporter, letting the same
+// binary run fleet-wide with site specific settings
+type fileConfig struct {
+	// Collectors lists the enabled collectors by name, all are enabled if empty
+	Collectors []string `yaml:"collectors"`
+	// Labels are constant labels attached to every metric, e.g. site or rack
+	Labels map[string]string `yaml:"labels"`
+	// Targets are the remote devices allowed to be scraped through /scrape
+	Targets []string `yaml:"targets"`
+}
+
+// reservedLabels are added by the exporter itself and can't be set in the config file
+var reservedLabels = []string{"asic", "sonic_scrape_target"}
+
+// namedCollector is a collector constructor, the name enabling it in the config
+// file and the subsystems of its metric names, used to run only the collectors
+// of the metrics requested by name
+type namedCollector struct {
+	name         string
+	subsystems   []string
+	newCollector func(logger *slog.Logger, redisClient *redis.Client, config collector.Config) prometheus.Collector
+}
+
+func named[T prometheus.Collector](name string, newCollector func(*slog.Logger, *redis.Client, collector.Config) T, subsystems ...string) namedCollector {
+	return namedCollector{
+		name:       name,
+		subsystems: subsystems,
+		newCollector: func(logger *slog.Logger, redisClient *redis.Client, config collector.Config) prometheus.Collector {
+			return newCollector(logger, redisClient, config)
+		},
+	}
+}
+
+// hostCollectors read chassis level hardware and sensors, process and system
+// stats, the reboot history, NTP and features, only available in the host namespace
+var hostCollectors = []namedCollector{
+	named("hw", collector.NewHwCollector, "hw"),
+	named("process", collector.NewProcessCollector, "process"),
+	named("system", collector.NewSystemCollector, "system", "services"),
+	named("reboot_cause", collector.NewRebootCauseCollector, "reboot"),
+	named("version", collector.NewVersionCollector, "version"),
+	named("ntp", collector.NewNtpCollector, "ntp"),
+	named("sensor", collector.NewSensorCollector, "sensor"),
+	named("critical_process", collector.NewCriticalProcessCollector, "critical_process"),
+	named("feature", collector.NewFeatureCollector, "feature", "snmp_agent", "telemetry"),
+}
+
+// asicCollectors read the per-ASIC databases
+var asicCollectors = []namedCollector{
+	named("interface", collector.NewInterfaceCollector, "interface"),
+	named("crm", collector.NewCrmCollector, "crm"),
+	named("transceiver", collector.NewTransceiverCollector, "transceiver"),
+	named("queue", collector.NewQueueCollector, "queue"),
+	named("pfcwd", collector.NewPfcWdCollector, "pfcwd"),
+	named("buffer", collector.NewBufferCollector, "buffer"),
+	named("fdb", collector.NewFdbCollector, "fdb"),
+	named("neighbor", collector.NewNeighborCollector, "neighbor"),
+	named("route", collector.NewRouteCollector, "route"),
+	named("portchannel", collector.NewPortChannelCollector, "portchannel"),
+	named("mclag", collector.NewMclagCollector, "mclag"),
+	named("vlan", collector.NewVlanCollector, "vlan"),
+	named("copp", collector.NewCoppCollector, "copp"),
+	named("acl_rule", collector.NewAclRuleCollector, "acl_rule"),
+	named("warmboot", collector.NewWarmbootCollector, "warmboot"),
+	named("storm_control", collector.NewStormControlCollector, "storm_control"),
+	named("vxlan", collector.NewVxlanCollector, "vxlan"),
+	named("sflow", collector.NewSflowCollector, "sflow"),
+	named("dhcp_relay", collector.NewDhcpRelayCollector, "dhcp_relay"),
+	named("gearbox", collector.NewGearboxCollector, "gearbox"),
+}
+
+// loadFileConfig reads and validates the config file at path. An empty path
+// yields the default config enabling all collectors without extra labels.
+func loadFileConfig(path string) (fileConfig, error) {
+	var config fileConfig
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err := config.validate(); err != nil {
+		return config, fmt.Errorf("invalid config file: %w", err)
+	}
+
+	return config, nil
+}
+
+func (c fileConfig) validate() error {
+	for _, name := range c.Collectors {
+		known := slices.ContainsFunc(append(slices.Clone(hostCollectors), asicCollectors...), func(n namedCollector) bool {
+			return n.name == name
+		})
+		if !known {
+			return fmt.Errorf("unknown collector %q", name)
+		}
+	}
+
+	for name := range c.Labels {
+		// keep labels usable by Prometheus servers without UTF-8 name support
+		if !model.LabelName(name).IsValidLegacy() {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if slices.Contains(reservedLabels, name) {
+			return fmt.Errorf("label %q is set by the exporter", name)
+		}
+	}
+
+	return nil
+}
+
+// collectorEnabled reports whether the collector called name is enabled
+func (c fileConfig) collectorEnabled(name string) bool {
+	return len(c.Collectors) == 0 || slices.Contains(c.Collectors, name)
+}
+
+// registerCollectors creates and registers the enabled collectors of candidates and returns them
+func registerCollectors(registerer collectorRegisterer, candidates []namedCollector, logger *slog.Logger, redisClient *redis.Client, config collector.Config, fileConfig fileConfig) []prometheus.Collector {
+	var collectors []prometheus.Collector
+	for _, candidate := range candidates {
+		if !fileConfig.collectorEnabled(candidate.name) {
+			continue
+		}
+
+		c := candidate.newCollector(logger, redisClient, config)
+		registerer.mustRegister(metricPrefixes(config.MetricNamespace(), candidate.subsystems), c)
+		collectors = append(collectors, c)
+	}
+
+	return collectors
+}
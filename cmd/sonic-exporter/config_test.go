@@ -0,0 +1,168 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mwennrich/sonic-exporter/internal/collector"
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestLoadFileConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected fileConfig
+		err      string
+	}{
+		{
+			name: "collectors and labels",
+			content: `
+collectors: [hw, interface]
+labels:
+  site: fra1
+  rack: r12
+`,
+			expected: fileConfig{
+				Collectors: []string{"hw", "interface"},
+				Labels:     map[string]string{"site": "fra1", "rack": "r12"},
+			},
+		},
+		{
+			name:     "targets",
+			content:  `targets: ["switch1:6379", "switch2:6379"]`,
+			expected: fileConfig{Targets: []string{"switch1:6379", "switch2:6379"}},
+		},
+		{name: "empty", content: "", expected: fileConfig{}},
+		{name: "unknown collector", content: "collectors: [hw, bgp]", err: `unknown collector "bgp"`},
+		{name: "unknown field", content: "collector: [hw]", err: "failed to parse config file"},
+		{name: "invalid label", content: "labels: {site-id: fra1}", err: `invalid label name "site-id"`},
+		{name: "reserved label", content: "labels: {asic: asic0}", err: `label "asic" is set by the exporter`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+
+			config, err := loadFileConfig(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(config, tt.expected) {
+				t.Errorf("got %+v, want %+v", config, tt.expected)
+			}
+		})
+	}
+
+	if _, err := loadFileConfig(filepath.Join(t.TempDir(), "missing.yml")); err == nil {
+		t.Errorf("missing config file should fail")
+	}
+
+	config, err := loadFileConfig("")
+	if err != nil || !config.collectorEnabled("hw") || !config.collectorEnabled("gearbox") {
+		t.Errorf("without config file all collectors should be enabled, got %+v, %v", config, err)
+	}
+}
+
+func TestRegisterCollectorsFileConfig(t *testing.T) {
+	s := miniredis.RunT(t)
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+	defer os.Unsetenv("REDIS_ADDRESS")
+
+	redisClient, err := redis.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create redis client: %v", err)
+	}
+	defer redisClient.Close()
+
+	config := fileConfig{
+		Collectors: []string{"hw", "crm"},
+		Labels:     map[string]string{"site": "fra1"},
+	}
+
+	logger := promslog.New(&promslog.Config{})
+	registry := newCollectorRegistry(prometheus.Gatherers{})
+	registerer := registry.registerer(config.Labels)
+
+	collectors := registerCollectors(registerer, hostCollectors, logger, redisClient, collector.Config{}, config)
+	collectors = append(collectors, registerCollectors(registerer, asicCollectors, logger, redisClient, collector.Config{}, config)...)
+	if len(collectors) != 2 {
+		t.Errorf("expected the hw and crm collectors, got %d collectors", len(collectors))
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	subsystems := map[string]bool{}
+	for _, family := range families {
+		subsystems[strings.Split(family.GetName(), "_")[1]] = true
+
+		for _, metric := range family.GetMetric() {
+			site := ""
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "site" {
+					site = label.GetValue()
+				}
+			}
+			if site != "fra1" {
+				t.Errorf("%s is missing the site label", family.GetName())
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(subsystems, map[string]bool{"hw": true, "crm": true}) {
+		t.Errorf("unexpected collectors registered: %v", subsystems)
+	}
+}
+
+func TestCollectorSubsystems(t *testing.T) {
+	s := miniredis.RunT(t)
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+	defer os.Unsetenv("REDIS_ADDRESS")
+
+	redisClient, err := redis.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create redis client: %v", err)
+	}
+	defer redisClient.Close()
+
+	logger := promslog.New(&promslog.Config{})
+	namespace := "switch"
+	config := collector.Config{Namespace: &namespace}
+
+	// every metric of a collector must be selectable through its subsystems
+	for _, candidate := range append(slices.Clone(hostCollectors), asicCollectors...) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(candidate.newCollector(logger, redisClient, config))
+
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("gather of %s failed: %v", candidate.name, err)
+		}
+
+		prefixes := metricPrefixes(namespace, candidate.subsystems)
+		for _, family := range families {
+			if !matchesAny(prefixes, map[string]struct{}{family.GetName(): {}}) {
+				t.Errorf("%s of the %s collector doesn't start with one of %v", family.GetName(), candidate.name, prefixes)
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+// nameFilterGatherer only returns the metric families whose name was requested.
+type nameFilterGatherer struct {
+	gatherer prometheus.Gatherer
+	names    map[string]struct{}
+}
+
+func (g nameFilterGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	filtered := make([]*dto.MetricFamily, 0, len(g.names))
+	for _, family := range families {
+		if _, ok := g.names[family.GetName()]; ok {
+			filtered = append(filtered, family)
+		}
+	}
+
+	return filtered, err
+}
+
+// nameSelector is a gatherer able to gather only the metric families of names
+// without collecting the others
+type nameSelector interface {
+	selectNames(names map[string]struct{}) prometheus.Gatherer
+}
+
+// collectorRegistry gathers the exporter's own metrics and the metrics of the
+// collectors registered to it. Unlike filtering the gathered families, selecting
+// names only runs the collectors whose metric names match one of them, so a
+// request for a few metrics doesn't read the redis tables of all collectors.
+type collectorRegistry struct {
+	exporter   prometheus.Gatherer
+	registry   *prometheus.Registry
+	collectors []registeredCollector
+}
+
+// registeredCollector is a registered collector with its constant labels and
+// the prefixes all of its metric names start with
+type registeredCollector struct {
+	collector prometheus.Collector
+	labels    prometheus.Labels
+	prefixes  []string
+}
+
+// collectorRegisterer registers collectors to a collectorRegistry adding
+// constant labels to their metrics
+type collectorRegisterer struct {
+	registry *collectorRegistry
+	labels   prometheus.Labels
+}
+
+func newCollectorRegistry(exporter prometheus.Gatherer) *collectorRegistry {
+	return &collectorRegistry{
+		exporter: exporter,
+		registry: prometheus.NewRegistry(),
+	}
+}
+
+// registerer returns a registerer adding labels to the metrics of its collectors
+func (r *collectorRegistry) registerer(labels prometheus.Labels) collectorRegisterer {
+	return collectorRegisterer{registry: r, labels: labels}
+}
+
+func (r *collectorRegistry) Gather() ([]*dto.MetricFamily, error) {
+	return prometheus.Gatherers{r.exporter, r.registry}.Gather()
+}
+
+// selectNames returns a gatherer of the metric families of names, it registers
+// the collectors of matching metric names to a registry of their own
+func (r *collectorRegistry) selectNames(names map[string]struct{}) prometheus.Gatherer {
+	selected := prometheus.NewRegistry()
+	for _, c := range r.collectors {
+		if !matchesAny(c.prefixes, names) {
+			continue
+		}
+		// already registered once, the collector is consistent
+		_ = prometheus.WrapRegistererWith(c.labels, selected).Register(c.collector)
+	}
+
+	return nameFilterGatherer{gatherer: prometheus.Gatherers{r.exporter, selected}, names: names}
+}
+
+// with returns a registerer adding labels on top of the labels of r
+func (r collectorRegisterer) with(labels prometheus.Labels) collectorRegisterer {
+	merged := make(prometheus.Labels, len(r.labels)+len(labels))
+	maps.Copy(merged, r.labels)
+	maps.Copy(merged, labels)
+
+	return collectorRegisterer{registry: r.registry, labels: merged}
+}
+
+// mustRegister registers collector, all of its metric names start with one of
+// prefixes. It panics if the collector can't be registered.
+func (r collectorRegisterer) mustRegister(prefixes []string, collector prometheus.Collector) {
+	prometheus.WrapRegistererWith(r.labels, r.registry.registry).MustRegister(collector)
+	r.registry.collectors = append(r.registry.collectors, registeredCollector{
+		collector: collector,
+		labels:    r.labels,
+		prefixes:  prefixes,
+	})
+}
+
+// metricPrefixes returns the prefixes of the metric names of subsystems in namespace
+func metricPrefixes(namespace string, subsystems []string) []string {
+	prefixes := make([]string, 0, len(subsystems))
+	for _, subsystem := range subsystems {
+		if namespace == "" {
+			prefixes = append(prefixes, subsystem+"_")
+		} else {
+			prefixes = append(prefixes, namespace+"_"+subsystem+"_")
+		}
+	}
+	return prefixes
+}
+
+func matchesAny(prefixes []string, names map[string]struct{}) bool {
+	for name := range names {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newMetricsHandler serves metrics from gatherer, honoring the name[] query
+// parameter to restrict the response to specific metric families. Names which
+// are not valid metric names are ignored.
+func newMetricsHandler(gatherer prometheus.Gatherer, opts promhttp.HandlerOpts) http.Handler {
+	unfiltered := promhttp.HandlerFor(gatherer, opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filtered, ok := filterGatherer(gatherer, r)
+		if !ok {
+			unfiltered.ServeHTTP(w, r)
+			return
+		}
+
+		promhttp.HandlerFor(filtered, opts).ServeHTTP(w, r)
+	})
+}
+
+// filterGatherer restricts gatherer to the metric families requested with the
+// name[] query parameter of r. Gatherers selecting names only collect those,
+// others are gathered fully and filtered. It returns false if no names were
+// requested.
+func filterGatherer(gatherer prometheus.Gatherer, r *http.Request) (prometheus.Gatherer, bool) {
+	requested := r.URL.Query()["name[]"]
+	if len(requested) == 0 {
+		return gatherer, false
+	}
+
+	names := make(map[string]struct{}, len(requested))
+	for _, name := range requested {
+		if model.IsValidMetricName(model.LabelValue(name)) {
+			names[name] = struct{}{}
+		}
+	}
+
+	if selector, ok := gatherer.(nameSelector); ok {
+		return selector.selectNames(names), true
+	}
+	return nameFilterGatherer{gatherer: gatherer, names: names}, true
+}
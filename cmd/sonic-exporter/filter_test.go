@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestMetricsHandlerNameFilter(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	for _, name := range []string{"sonic_hw_collector_success", "sonic_crm_collector_success", "sonic_interface_collector_success"} {
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: name})
+		gauge.Set(1)
+		registry.MustRegister(gauge)
+	}
+
+	server := httptest.NewServer(newMetricsHandler(registry, promhttp.HandlerOpts{}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		query    string
+		included []string
+		excluded []string
+	}{
+		{
+			name:     "no filter",
+			query:    "",
+			included: []string{"sonic_hw_collector_success", "sonic_crm_collector_success", "sonic_interface_collector_success"},
+		},
+		{
+			name:     "single name",
+			query:    "?name[]=sonic_hw_collector_success",
+			included: []string{"sonic_hw_collector_success"},
+			excluded: []string{"sonic_crm_collector_success", "sonic_interface_collector_success"},
+		},
+		{
+			name:     "multiple names with unknown and invalid",
+			query:    "?name[]=sonic_hw_collector_success&name[]=sonic_crm_collector_success&name[]=unknown_metric&name[]=0invalid-",
+			included: []string{"sonic_hw_collector_success", "sonic_crm_collector_success"},
+			excluded: []string{"sonic_interface_collector_success"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.Client().Get(server.URL + tt.query)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != 200 {
+				t.Fatalf("unexpected status code: %d", resp.StatusCode)
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			for _, name := range tt.included {
+				if !strings.Contains(string(body), name+" 1") {
+					t.Errorf("expected %s in response", name)
+				}
+			}
+			for _, name := range tt.excluded {
+				if strings.Contains(string(body), name) {
+					t.Errorf("unexpected %s in response", name)
+				}
+			}
+		})
+	}
+}
+
+// countingCollector emits one gauge and counts how often it was collected
+type countingCollector struct {
+	desc      *prometheus.Desc
+	collected atomic.Int32
+}
+
+func (c *countingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *countingCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collected.Add(1)
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+}
+
+func TestCollectorRegistrySelectNames(t *testing.T) {
+	exporter := prometheus.NewRegistry()
+	exporter.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "sonic_exporter_up", Help: "up"}))
+
+	registry := newCollectorRegistry(exporter)
+	hw := &countingCollector{desc: prometheus.NewDesc("sonic_hw_collector_success", "hw", nil, nil)}
+	crm := &countingCollector{desc: prometheus.NewDesc("sonic_crm_collector_success", "crm", nil, nil)}
+	registerer := registry.registerer(prometheus.Labels{"site": "fra1"}).with(prometheus.Labels{"asic": "asic0"})
+	registerer.mustRegister(metricPrefixes("sonic", []string{"hw"}), hw)
+	registerer.mustRegister(metricPrefixes("sonic", []string{"crm"}), crm)
+
+	server := httptest.NewServer(newMetricsHandler(registry, promhttp.HandlerOpts{}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "?name[]=sonic_hw_collector_success&name[]=sonic_exporter_up")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	for _, name := range []string{`sonic_hw_collector_success{asic="asic0",site="fra1"} 1`, "sonic_exporter_up 0"} {
+		if !strings.Contains(string(body), name) {
+			t.Errorf("expected %s in response", name)
+		}
+	}
+	if strings.Contains(string(body), "sonic_crm_collector_success") {
+		t.Errorf("unexpected sonic_crm_collector_success in response")
+	}
+	if hw.collected.Load() != 1 || crm.collected.Load() != 0 {
+		t.Errorf("expected only the hw collector to run, hw ran %d and crm %d times", hw.collected.Load(), crm.collected.Load())
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// hostnameLabel is the label the SONiC hostname is attached as
+	hostnameLabel = "hostname"
+	// hostnameRefreshInterval is how long a hostname read from redis is reused
+	hostnameRefreshInterval = 5 * time.Minute
+	// hostnameReadTimeout bounds reading the hostname from redis
+	hostnameReadTimeout = 1 * time.Second
+)
+
+// hostnameGatherer adds the hostname of DEVICE_METADATA|localhost to all metrics
+// of gatherer. The hostname is cached and re-read every hostnameRefreshInterval,
+// the last known hostname is kept if reading fails. Metrics are returned without
+// the label until a hostname was read, and metrics already carrying a hostname
+// label, e.g. from the config file, keep theirs.
+type hostnameGatherer struct {
+	gatherer    prometheus.Gatherer
+	redisClient *redis.Client
+	logger      *slog.Logger
+	hostname    string
+	lastRead    time.Time
+	mu          sync.Mutex
+}
+
+func newHostnameGatherer(gatherer prometheus.Gatherer, redisClient *redis.Client, logger *slog.Logger) *hostnameGatherer {
+	return &hostnameGatherer{
+		gatherer:    gatherer,
+		redisClient: redisClient,
+		logger:      logger,
+	}
+}
+
+func (g *hostnameGatherer) Gather() ([]*dto.MetricFamily, error) {
+	return g.addHostname(g.gatherer.Gather())
+}
+
+// selectNames keeps the selection of names of the wrapped gatherer, if it has one
+func (g *hostnameGatherer) selectNames(names map[string]struct{}) prometheus.Gatherer {
+	var selected prometheus.Gatherer = nameFilterGatherer{gatherer: g.gatherer, names: names}
+	if selector, ok := g.gatherer.(nameSelector); ok {
+		selected = selector.selectNames(names)
+	}
+
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return g.addHostname(selected.Gather())
+	})
+}
+
+// addHostname adds the hostname label to the metrics of families
+func (g *hostnameGatherer) addHostname(families []*dto.MetricFamily, err error) ([]*dto.MetricFamily, error) {
+	hostname := g.currentHostname()
+	if hostname == "" {
+		return families, err
+	}
+	labelName := hostnameLabel
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			hasHostname := slices.ContainsFunc(metric.GetLabel(), func(label *dto.LabelPair) bool {
+				return label.GetName() == hostnameLabel
+			})
+			if hasHostname {
+				continue
+			}
+
+			// keep the labels sorted by name as the registry returns them
+			position, _ := slices.BinarySearchFunc(metric.GetLabel(), hostnameLabel, func(label *dto.LabelPair, name string) int {
+				return strings.Compare(label.GetName(), name)
+			})
+			metric.Label = slices.Insert(metric.Label, position, &dto.LabelPair{Name: &labelName, Value: &hostname})
+		}
+	}
+
+	return families, err
+}
+
+// currentHostname returns the cached hostname, re-reading it from redis once it expired
+func (g *hostnameGatherer) currentHostname() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.lastRead.IsZero() && time.Since(g.lastRead) < hostnameRefreshInterval {
+		return g.hostname
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hostnameReadTimeout)
+	defer cancel()
+
+	metadata, err := g.redisClient.HgetAllFromDb(ctx, "CONFIG_DB", "DEVICE_METADATA|localhost")
+	if err != nil {
+		g.logger.ErrorContext(ctx, "Error reading hostname", "err", err)
+		return g.hostname
+	}
+
+	if hostname := metadata["hostname"]; hostname != "" {
+		g.hostname = hostname
+	}
+	g.lastRead = time.Now()
+
+	return g.hostname
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestHostnameGatherer(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	redisServer.DB(4).HSet("DEVICE_METADATA|localhost", "hostname", "sw1")
+
+	redisClient, err := redis.NewTargetClient(redisServer.Addr())
+	if err != nil {
+		t.Fatalf("failed to create redis client: %v", err)
+	}
+	defer redisClient.Close()
+
+	registry := prometheus.NewRegistry()
+	up := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "sonic_test_up", Help: "Test metric."}, []string{"device"})
+	up.WithLabelValues("Ethernet0").Set(1)
+	registry.MustRegister(up)
+	labeled := prometheus.NewGauge(prometheus.GaugeOpts{Name: "sonic_test_labeled", Help: "Test metric.", ConstLabels: prometheus.Labels{"hostname": "configured"}})
+	labeled.Set(1)
+	registry.MustRegister(labeled)
+
+	t.Run("disabled", func(t *testing.T) {
+		expected := `
+# HELP sonic_test_up Test metric.
+# TYPE sonic_test_up gauge
+sonic_test_up{device="Ethernet0"} 1
+`
+		if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "sonic_test_up"); err != nil {
+			t.Errorf("unexpected metrics without hostname label: %v", err)
+		}
+	})
+
+	gatherer := newHostnameGatherer(registry, redisClient, promslog.New(&promslog.Config{}))
+
+	t.Run("enabled", func(t *testing.T) {
+		expected := `
+# HELP sonic_test_labeled Test metric.
+# TYPE sonic_test_labeled gauge
+sonic_test_labeled{hostname="configured"} 1
+# HELP sonic_test_up Test metric.
+# TYPE sonic_test_up gauge
+sonic_test_up{device="Ethernet0",hostname="sw1"} 1
+`
+		if err := testutil.GatherAndCompare(gatherer, strings.NewReader(expected)); err != nil {
+			t.Errorf("unexpected metrics with hostname label: %v", err)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		redisServer.DB(4).HSet("DEVICE_METADATA|localhost", "hostname", "sw2")
+		if hostname := gatherer.currentHostname(); hostname != "sw1" {
+			t.Errorf("expected cached hostname sw1, got %q", hostname)
+		}
+
+		gatherer.lastRead = time.Now().Add(-hostnameRefreshInterval)
+		if hostname := gatherer.currentHostname(); hostname != "sw2" {
+			t.Errorf("expected refreshed hostname sw2, got %q", hostname)
+		}
+
+		// the last known hostname is kept when redis is gone
+		redisServer.Close()
+		gatherer.lastRead = time.Now().Add(-hostnameRefreshInterval)
+		if hostname := gatherer.currentHostname(); hostname != "sw2" {
+			t.Errorf("expected last known hostname sw2, got %q", hostname)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// jsonMetricFamily is a metric family as served by /metrics.json
+type jsonMetricFamily struct {
+	Name    string       `json:"name"`
+	Help    string       `json:"help"`
+	Type    string       `json:"type"`
+	Metrics []jsonMetric `json:"metrics"`
+}
+
+// jsonMetric is a single series of a family. Values are strings like in the
+// Prometheus HTTP API, as JSON can't represent NaN and infinities. Counters,
+// gauges and untyped metrics carry a value, histograms and summaries their
+// count, sum and buckets or quantiles.
+type jsonMetric struct {
+	Labels    map[string]string `json:"labels"`
+	Value     string            `json:"value,omitempty"`
+	Count     string            `json:"count,omitempty"`
+	Sum       string            `json:"sum,omitempty"`
+	Buckets   map[string]string `json:"buckets,omitempty"`
+	Quantiles map[string]string `json:"quantiles,omitempty"`
+}
+
+// newJSONMetricsHandler serves the metrics of gatherer as JSON for consumers not
+// speaking the Prometheus exposition formats. Like the metrics handler it
+// honors the name[] query parameter.
+func newJSONMetricsHandler(gatherer prometheus.Gatherer, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filtered, _ := filterGatherer(gatherer, r)
+
+		families, err := filtered.Gather()
+		if err != nil {
+			http.Error(w, "An error has occurred while gathering metrics:\n\n"+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		jsonFamilies := make([]jsonMetricFamily, 0, len(families))
+		for _, family := range families {
+			jsonFamilies = append(jsonFamilies, newJSONMetricFamily(family))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(jsonFamilies); err != nil {
+			logger.ErrorContext(r.Context(), "Error writing response", "err", err)
+		}
+	})
+}
+
+func newJSONMetricFamily(family *dto.MetricFamily) jsonMetricFamily {
+	jsonFamily := jsonMetricFamily{
+		Name:    family.GetName(),
+		Help:    family.GetHelp(),
+		Type:    jsonMetricType(family.GetType()),
+		Metrics: make([]jsonMetric, 0, len(family.GetMetric())),
+	}
+
+	for _, metric := range family.GetMetric() {
+		jsonMetric := jsonMetric{Labels: make(map[string]string, len(metric.GetLabel()))}
+		for _, label := range metric.GetLabel() {
+			jsonMetric.Labels[label.GetName()] = label.GetValue()
+		}
+
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			jsonMetric.Value = formatValue(metric.GetCounter().GetValue())
+		case dto.MetricType_GAUGE:
+			jsonMetric.Value = formatValue(metric.GetGauge().GetValue())
+		case dto.MetricType_UNTYPED:
+			jsonMetric.Value = formatValue(metric.GetUntyped().GetValue())
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			histogram := metric.GetHistogram()
+			jsonMetric.Count = strconv.FormatUint(histogram.GetSampleCount(), 10)
+			jsonMetric.Sum = formatValue(histogram.GetSampleSum())
+			jsonMetric.Buckets = make(map[string]string, len(histogram.GetBucket()))
+			for _, bucket := range histogram.GetBucket() {
+				jsonMetric.Buckets[formatValue(bucket.GetUpperBound())] = strconv.FormatUint(bucket.GetCumulativeCount(), 10)
+			}
+		case dto.MetricType_SUMMARY:
+			summary := metric.GetSummary()
+			jsonMetric.Count = strconv.FormatUint(summary.GetSampleCount(), 10)
+			jsonMetric.Sum = formatValue(summary.GetSampleSum())
+			jsonMetric.Quantiles = make(map[string]string, len(summary.GetQuantile()))
+			for _, quantile := range summary.GetQuantile() {
+				jsonMetric.Quantiles[formatValue(quantile.GetQuantile())] = formatValue(quantile.GetValue())
+			}
+		}
+
+		jsonFamily.Metrics = append(jsonFamily.Metrics, jsonMetric)
+	}
+
+	return jsonFamily
+}
+
+// jsonMetricType returns the type of a family as named in the text exposition format
+func jsonMetricType(metricType dto.MetricType) string {
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return "counter"
+	case dto.MetricType_GAUGE:
+		return "gauge"
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		return "histogram"
+	case dto.MetricType_SUMMARY:
+		return "summary"
+	default:
+		return "untyped"
+	}
+}
+
+// formatValue formats a sample value like the Prometheus HTTP API
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
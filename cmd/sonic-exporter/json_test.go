@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestJSONMetricsHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	up := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "sonic_test_up", Help: "Test gauge."}, []string{"device"})
+	up.WithLabelValues("Ethernet0").Set(1)
+	up.WithLabelValues("Ethernet4").Set(math.NaN())
+	packets := prometheus.NewCounter(prometheus.CounterOpts{Name: "sonic_test_packets_total", Help: "Test counter."})
+	packets.Add(42)
+	registry.MustRegister(up, packets)
+
+	server := httptest.NewServer(newJSONMetricsHandler(registry, promslog.New(&promslog.Config{})))
+	defer server.Close()
+
+	get := func(query string) []jsonMetricFamily {
+		resp, err := server.Client().Get(server.URL + "/metrics.json" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.StatusCode)
+		}
+		if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("expected content type application/json, got %q", contentType)
+		}
+
+		var families []jsonMetricFamily
+		if err := json.NewDecoder(resp.Body).Decode(&families); err != nil {
+			t.Fatalf("response is not valid JSON: %v", err)
+		}
+		return families
+	}
+
+	expected := []jsonMetricFamily{
+		{
+			Name: "sonic_test_packets_total",
+			Help: "Test counter.",
+			Type: "counter",
+			Metrics: []jsonMetric{
+				{Labels: map[string]string{}, Value: "42"},
+			},
+		},
+		{
+			Name: "sonic_test_up",
+			Help: "Test gauge.",
+			Type: "gauge",
+			Metrics: []jsonMetric{
+				{Labels: map[string]string{"device": "Ethernet0"}, Value: "1"},
+				{Labels: map[string]string{"device": "Ethernet4"}, Value: "NaN"},
+			},
+		},
+	}
+	if families := get(""); !reflect.DeepEqual(families, expected) {
+		t.Errorf("unexpected metrics:\ngot  %+v\nwant %+v", families, expected)
+	}
+
+	if families := get("?name[]=sonic_test_up"); len(families) != 1 || families[0].Name != "sonic_test_up" {
+		t.Errorf("expected only sonic_test_up, got %+v", families)
+	}
+}
@@ -5,46 +5,217 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/mwennrich/sonic-exporter/internal/collector"
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
 	"github.com/prometheus/client_golang/prometheus"
+	promcollectors "github.com/prometheus/client_golang/prometheus/collectors"
+	versioncollector "github.com/prometheus/client_golang/prometheus/collectors/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/promslog"
 	"github.com/prometheus/common/promslog/flag"
+	"github.com/prometheus/common/version"
 	"github.com/prometheus/exporter-toolkit/web"
 	webflag "github.com/prometheus/exporter-toolkit/web/kingpinflag"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run runs the exporter and returns its exit code, so the deferred Close calls
+// release the redis connections on every exit path
+func run() int {
 	var (
-		webConfig   = webflag.AddFlags(kingpin.CommandLine, ":9101")
-		metricsPath = kingpin.Flag("web.telemetry-path", "Path under which to expose metrics.").Default("/metrics").String()
+		webConfig         = webflag.AddFlags(kingpin.CommandLine, ":9101")
+		metricsPath       = kingpin.Flag("web.telemetry-path", "Path under which to expose metrics.").Default("/metrics").String()
+		enableOpenMetrics = kingpin.Flag("web.enable-openmetrics", "Enable OpenMetrics exposition, including _created samples for counters.").Default("false").Bool()
+		cacheDuration     = kingpin.Flag("collector.cache-duration", "How long scraped metrics are served from cache, 0 disables caching.").Default("15s").Duration()
+		cacheAdaptive     = kingpin.Flag("collector.cache-adaptive", "Serve metrics from cache for half the observed scrape interval instead of the cache duration.").Default("false").Bool()
+		cacheMinDuration  = kingpin.Flag("collector.cache-min-duration", "Lower bound of the adaptive cache duration.").Default("1s").Duration()
+		cacheMaxDuration  = kingpin.Flag("collector.cache-max-duration", "Upper bound of the adaptive cache duration.").Default("60s").Duration()
+		precision         = kingpin.Flag("collector.precision", "Number of decimal places derived gauges (ratios, percentages, converted units) are rounded to, 0 keeps full precision.").Default("0").Int()
+		singleAsicLabel   = kingpin.Flag("collector.single-asic-label", "Add asic=\"asic0\" label to per-ASIC metrics on single-ASIC systems.").Default("true").Bool()
+		redisTimeout      = kingpin.Flag("redis.timeout", "Timeout for the redis calls of a single collector scrape, 0 disables it.").Default("5s").Duration()
+		readyMaxFailing   = kingpin.Flag("web.ready.max-failing-collectors", "Number of failing collectors /readyz tolerates as degraded, -1 tolerates any partial failure.").Default("-1").Int()
+		readyDegraded     = kingpin.Flag("web.ready.degraded-status", "Status code /readyz answers with while degraded, e.g. 200 or 503.").Default("200").Int()
+		versionFile       = kingpin.Flag("collector.version-file", "Path of SONiC's sonic_version.yml describing the image version.").Default("/etc/sonic/sonic_version.yml").String()
+		uptimeFile        = kingpin.Flag("collector.uptime-file", "Path the system uptime is read from, empty disables it.").Default("/proc/uptime").String()
+		redisScan         = kingpin.Flag("redis.scan", "List redis keys with SCAN, which doesn't block redis on large databases, instead of KEYS.").Default("true").Bool()
+		redisReadCache    = kingpin.Flag("redis.read-cache-ttl", "Window in which collectors reading the same redis hash share a single read, 0 disables sharing.").Default("0s").Duration()
+		redisCheckOnStart = kingpin.Flag("redis.check-on-start", "Ping all redis databases at startup and exit if one is unreachable.").Default("false").Bool()
+		redisCheckWait    = kingpin.Flag("redis.check-on-start.wait", "How long the startup check retries unreachable redis before exiting, 0 exits right away.").Default("0s").Duration()
+		redisInstrument   = kingpin.Flag("redis.instrumentation", "Expose metrics about the redis servers the exporter reads from.").Default("false").Bool()
+		shutdownTimeout   = kingpin.Flag("web.shutdown-timeout", "How long to wait for in-flight scrapes on SIGINT/SIGTERM before stopping.").Default("10s").Duration()
+		scrapeEnabled     = kingpin.Flag("web.scrape.enabled", "Serve /scrape to scrape the redis of remote SONiC devices given by the target parameter.").Default("false").Bool()
+		scrapeTargets     = kingpin.Flag("web.scrape.target", "Address of a remote device allowed to be scraped through /scrape, repeatable. Added to the targets of the config file.").Strings()
+		scrapeMaxTargets  = kingpin.Flag("web.scrape.max-targets", "Number of remote devices whose redis client and collectors are kept, 0 keeps all.").Default("64").Int()
+		scrapeIdleTimeout = kingpin.Flag("web.scrape.idle-timeout", "How long the redis client and collectors of a remote device not scraped are kept, 0 keeps them.").Default("10m").Duration()
+		hostnameLabelFlag = kingpin.Flag("metrics.hostname-label", "Add the SONiC hostname read from DEVICE_METADATA in redis as hostname label to all metrics.").Default("false").Bool()
+		metricsNamespace  = kingpin.Flag("metrics.namespace", "Prefix of the exported metric names, empty drops the prefix.").Default("sonic").String()
+		interfaceInclude  = kingpin.Flag("collector.interface.include", "Regexp of the interfaces the interface collector exports series of, all by default.").Regexp()
+		interfaceExclude  = kingpin.Flag("collector.interface.exclude", "Regexp of the interfaces the interface collector drops series of, wins over the include regexp.").Regexp()
+		psuSerialLabel    = kingpin.Flag("collector.hw.psu-serial-label", "Key PSU series by serial instead of slot, which can shift across reboots.").Default("false").Bool()
+		watermarkClear    = kingpin.Flag("collector.watermark.clear", "Clear the persistent buffer watermarks after every scrape, which also resets them for other consumers like watermarkstat.").Default("false").Bool()
+		once              = kingpin.Flag("once", "Scrape all enabled collectors once, print the metrics to stdout and exit non-zero if a collector failed.").Default("false").Bool()
+		configFile        = kingpin.Flag("config.file", "Path of a YAML file selecting the enabled collectors and constant labels added to every metric.").Default("").String()
 	)
 
 	promslogConfig := &promslog.Config{}
 	flag.AddFlags(kingpin.CommandLine, promslogConfig)
+	kingpin.Version(version.Print("sonic-exporter"))
 	kingpin.HelpFlag.Short('h')
 	kingpin.CommandLine.UsageWriter(os.Stdout)
 	kingpin.Parse()
 
 	logger := promslog.New(promslogConfig)
+	logger.InfoContext(context.Background(), "Starting sonic-exporter", "version", version.Info())
+
+	if err := collector.ValidateNamespace(*metricsNamespace); err != nil {
+		logger.ErrorContext(context.Background(), "Error validating flags", "err", err)
+		return 1
+	}
+	if *cacheMinDuration > *cacheMaxDuration {
+		logger.ErrorContext(context.Background(), "Error validating flags", "err", "cache min duration exceeds cache max duration")
+		return 1
+	}
+
+	fileConfig, err := loadFileConfig(*configFile)
+	if err != nil {
+		logger.ErrorContext(context.Background(), "Error loading config file", "err", err)
+		return 1
+	}
+
+	registry, registerer, collectorRegisterer := newRegistry(*metricsNamespace, fileConfig.Labels)
+
+	redisClient, err := redis.NewClient()
+	if err != nil {
+		logger.ErrorContext(context.Background(), "Error creating redis client", "err", err)
+		return 1
+	}
+	defer redisClient.Close()
+	redisOpts := redisOptions{scan: *redisScan, readCacheTTL: *redisReadCache}
+	redisOpts.apply(redisClient)
+	pingers := []redisPinger{redisClient}
+
+	namespaces, err := redis.Namespaces()
+	if err != nil {
+		logger.ErrorContext(context.Background(), "Error reading redis namespaces", "err", err)
+		return 1
+	}
+
+	collectorConfig := collector.Config{
+		CacheDuration:    *cacheDuration,
+		CacheAdaptive:    *cacheAdaptive,
+		CacheMinDuration: *cacheMinDuration,
+		CacheMaxDuration: *cacheMaxDuration,
+		Timeout:          *redisTimeout,
+		Precision:        *precision,
+		VersionFile:      *versionFile,
+		UptimeFile:       *uptimeFile,
+		Namespace:        metricsNamespace,
+		InterfaceInclude: *interfaceInclude,
+		InterfaceExclude: *interfaceExclude,
+		PsuSerialLabel:   *psuSerialLabel,
+		WatermarkClear:   *watermarkClear,
+	}
+
+	// Collectors are not wrapped for parallel scrapes, the registry already runs the
+	// Collect of every registered collector in its own goroutine.
+	collectors := registerCollectors(collectorRegisterer, hostCollectors, logger, redisClient, collectorConfig, fileConfig)
+	registerer.MustRegister(newListenInfo(*metricsNamespace, webConfig))
+
+	if *redisInstrument {
+		registerRedisCollectors(collectorRegisterer, logger, redisClient, collectorConfig)
+	}
+
+	if len(namespaces) == 0 {
+		asicRegisterer := collectorRegisterer
+		if *singleAsicLabel {
+			asicRegisterer = collectorRegisterer.with(prometheus.Labels{"asic": "asic0"})
+		}
+		collectors = append(collectors, registerCollectors(asicRegisterer, asicCollectors, logger, redisClient, collectorConfig, fileConfig)...)
+	}
+
+	for _, namespace := range namespaces {
+		namespaceClient, err := redis.NewNamespaceClient(namespace)
+		if err != nil {
+			logger.ErrorContext(context.Background(), "Error creating redis client", "namespace", namespace.Name, "err", err)
+			return 1
+		}
+		defer namespaceClient.Close()
+		redisOpts.apply(namespaceClient)
+		pingers = append(pingers, namespaceClient)
+
+		namespaceRegisterer := collectorRegisterer.with(prometheus.Labels{"asic": namespace.Name})
+		collectors = append(collectors, registerCollectors(namespaceRegisterer, asicCollectors, logger, namespaceClient, collectorConfig, fileConfig)...)
+		if *redisInstrument {
+			registerRedisCollectors(namespaceRegisterer, logger, namespaceClient, collectorConfig)
+		}
+	}
+
+	if *redisCheckOnStart {
+		if err := checkRedis(context.Background(), logger, pingers, *redisCheckWait); err != nil {
+			logger.ErrorContext(context.Background(), "Error connecting to redis, check REDIS_ADDRESS, REDIS_SOCKET and SONIC_DB_CONFIG", "err", err)
+			return 1
+		}
+	}
+
+	var healthReporters []healthReporter
+	for _, c := range collectors {
+		if reporter, ok := c.(healthReporter); ok {
+			healthReporters = append(healthReporters, reporter)
+		}
+	}
+	registerer.MustRegister(newExporterUp(*metricsNamespace, healthReporters))
+
+	var gatherer prometheus.Gatherer = registry
+	if *hostnameLabelFlag {
+		gatherer = newHostnameGatherer(registry, redisClient, logger)
+	}
+
+	if *once {
+		return runOnce(gatherer, os.Stdout)
+	}
 
-	interfaceCollector := collector.NewInterfaceCollector(logger)
-	hwCollector := collector.NewHwCollector(logger)
-	crmCollector := collector.NewCrmCollector(logger)
-	prometheus.MustRegister(interfaceCollector)
-	prometheus.MustRegister(hwCollector)
-	prometheus.MustRegister(crmCollector)
+	handlerOpts := promhttp.HandlerOpts{
+		EnableOpenMetrics:                   *enableOpenMetrics,
+		EnableOpenMetricsTextCreatedSamples: *enableOpenMetrics,
+	}
+	http.Handle(*metricsPath, instrumentMetricsHandler(registerer, newMetricsHandler(gatherer, handlerOpts)))
+	http.Handle("/metrics.json", newJSONMetricsHandler(gatherer, logger))
+	if *scrapeEnabled {
+		targetOpts := targetOptions{
+			allowed:     append(slices.Clone(*scrapeTargets), fileConfig.Targets...),
+			maxTargets:  *scrapeMaxTargets,
+			idleTimeout: *scrapeIdleTimeout,
+		}
+		if len(targetOpts.allowed) == 0 {
+			logger.ErrorContext(context.Background(), "Error validating flags", "err", "/scrape is enabled without allowed targets")
+			return 1
+		}
 
-	http.Handle(*metricsPath, promhttp.Handler())
+		targets := newTargetHandler(logger, collectorConfig, fileConfig, redisOpts, *hostnameLabelFlag, targetOpts, handlerOpts)
+		defer targets.Close()
+		http.Handle("/scrape", targets)
+	}
+	http.Handle("/healthz", newHealthHandler())
+	http.Handle("/readyz", newReadyHandler(pingers, healthReporters, readinessConfig{
+		MaxFailing:     *readyMaxFailing,
+		DegradedStatus: *readyDegraded,
+	}))
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		_, err := w.Write([]byte(`<html>
              <head><title>Sonic Exporter</title></head>
              <body>
              <h1>Sonic Exporter</h1>
              <p><a href='` + *metricsPath + `'>Metrics</a></p>
+             <p><a href='/metrics.json'>Metrics as JSON</a></p>
              </body>
              </html>`))
 		if err != nil {
@@ -55,8 +226,63 @@ func main() {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
-	if err := web.ListenAndServe(srv, webConfig, slog.Default()); err != nil {
-		logger.ErrorContext(context.Background(), "Error starting HTTP server", "err", err)
-		os.Exit(1)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listen := func() error {
+		return web.ListenAndServe(srv, webConfig, slog.Default())
+	}
+	if err := runServer(ctx, srv, listen, *shutdownTimeout, logger); err != nil {
+		logger.ErrorContext(context.Background(), "Error running HTTP server", "err", err)
+		return 1
 	}
+
+	// The deferred Close calls release the redis connections on return
+	logger.InfoContext(context.Background(), "Closing redis connections")
+	return 0
+}
+
+// newRegistry returns the registry the exporter serves and two registerers adding
+// the constant labels to every metric registered through them, one for the
+// exporter's own metrics and one for the collectors reading redis, which are
+// only run if a request filtered by name asks for one of their metrics. The Go,
+// process and build info collectors are registered explicitly, build info is
+// prefixed with namespace like all exporter metrics.
+func newRegistry(namespace string, labels prometheus.Labels) (*collectorRegistry, prometheus.Registerer, collectorRegisterer) {
+	exporterRegistry := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(labels, exporterRegistry)
+	registry := newCollectorRegistry(exporterRegistry)
+
+	registerer.MustRegister(
+		promcollectors.NewGoCollector(),
+		promcollectors.NewProcessCollector(promcollectors.ProcessCollectorOpts{}),
+		versioncollector.NewCollector(prometheus.BuildFQName(namespace, "", "exporter")),
+	)
+
+	return registry, registerer, registry.registerer(labels)
+}
+
+// redisOptions are the settings of the redis clients given by flags
+type redisOptions struct {
+	scan         bool
+	readCacheTTL time.Duration
+}
+
+// apply configures redisClient with the options
+func (o redisOptions) apply(redisClient *redis.Client) {
+	redisClient.SetScanKeys(o.scan)
+	redisClient.SetReadCache(o.readCacheTTL)
+}
+
+// registerRedisCollectors registers the redis server metrics and the command
+// metrics of the commands issued through redisClient
+func registerRedisCollectors(registerer collectorRegisterer, logger *slog.Logger, redisClient *redis.Client, config collector.Config) {
+	commandMetrics := collector.NewRedisCommandMetrics(config)
+	redisClient.SetCommandObserver(commandMetrics.Observe)
+	redisClient.SetReconnectObserver(commandMetrics.ObserveReconnect)
+
+	prefixes := metricPrefixes(config.MetricNamespace(), []string{"redis"})
+	registerer.mustRegister(prefixes, collector.NewRedisCollector(logger, redisClient, config))
+	registerer.mustRegister(prefixes, commandMetrics)
 }
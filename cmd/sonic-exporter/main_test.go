@@ -0,0 +1,176 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mwennrich/sonic-exporter/internal/collector"
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/exporter-toolkit/web"
+)
+
+func TestRegisterAsicCollectors(t *testing.T) {
+	s := miniredis.RunT(t)
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+	defer os.Unsetenv("REDIS_ADDRESS")
+
+	logger := promslog.New(&promslog.Config{})
+	registry := newCollectorRegistry(prometheus.Gatherers{})
+
+	for _, asic := range []string{"asic0", "asic1"} {
+		redisClient, err := redis.NewClient()
+		if err != nil {
+			t.Fatalf("failed to create redis client: %v", err)
+		}
+		defer redisClient.Close()
+
+		registerCollectors(registry.registerer(prometheus.Labels{"asic": asic}), asicCollectors, logger, redisClient, collector.Config{}, fileConfig{})
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	asics := map[string]bool{}
+	for _, family := range families {
+		if family.GetName() != "sonic_crm_collector_success" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "asic" {
+					asics[label.GetValue()] = true
+				}
+			}
+		}
+	}
+
+	if !asics["asic0"] || !asics["asic1"] || len(asics) != 2 {
+		t.Errorf("expected sonic_crm_collector_success for asic0 and asic1, got %v", asics)
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	s := miniredis.RunT(t)
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+	defer os.Unsetenv("REDIS_ADDRESS")
+
+	redisClient, err := redis.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create redis client: %v", err)
+	}
+	defer redisClient.Close()
+
+	registry, _, collectorRegisterer := newRegistry("sonic", prometheus.Labels{"site": "fra1"})
+	registerCollectors(collectorRegisterer, hostCollectors, promslog.New(&promslog.Config{}), redisClient, collector.Config{}, fileConfig{})
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	found := map[string]bool{}
+	for _, family := range families {
+		found[family.GetName()] = true
+	}
+
+	for _, name := range []string{"sonic_hw_collector_success", "sonic_exporter_build_info", "go_goroutines", "process_start_time_seconds"} {
+		if !found[name] {
+			t.Errorf("%s not gathered from the registry", name)
+		}
+	}
+
+	// nothing is registered to the global default registry
+	defaultFamilies, _ := prometheus.DefaultGatherer.Gather()
+	for _, family := range defaultFamilies {
+		if strings.HasPrefix(family.GetName(), "sonic_") {
+			t.Errorf("%s gathered from the default registry", family.GetName())
+		}
+	}
+}
+
+func TestExporterMetricsNamespace(t *testing.T) {
+	addresses := []string{"0.0.0.0:9101"}
+	systemdSocket := false
+
+	tests := []struct {
+		namespace string
+		expected  []string
+	}{
+		{namespace: "switch", expected: []string{"switch_exporter_build_info", "switch_exporter_listen_info", "switch_exporter_up"}},
+		{namespace: "", expected: []string{"exporter_build_info", "exporter_listen_info", "exporter_up"}},
+	}
+
+	for _, test := range tests {
+		registry, registerer, _ := newRegistry(test.namespace, nil)
+		registerer.MustRegister(
+			newListenInfo(test.namespace, &web.FlagConfig{WebListenAddresses: &addresses, WebSystemdSocket: &systemdSocket}),
+			newExporterUp(test.namespace, nil),
+		)
+
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+
+		found := map[string]bool{}
+		for _, family := range families {
+			found[family.GetName()] = true
+		}
+
+		for _, name := range test.expected {
+			if !found[name] {
+				t.Errorf("%s not gathered with namespace %q", name, test.namespace)
+			}
+		}
+		if found["sonic_exporter_build_info"] || found["sonic_exporter_listen_info"] || found["sonic_exporter_up"] {
+			t.Errorf("exporter metrics gathered without namespace %q", test.namespace)
+		}
+	}
+}
+
+// slowCollector emits one gauge after a delay, like a collector on a cold cache
+type slowCollector struct {
+	desc  *prometheus.Desc
+	delay time.Duration
+}
+
+func (c slowCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c slowCollector) Collect(ch chan<- prometheus.Metric) {
+	time.Sleep(c.delay)
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+}
+
+func TestRegistryCollectsConcurrently(t *testing.T) {
+	const delay = 200 * time.Millisecond
+
+	registry := prometheus.NewRegistry()
+	for _, name := range []string{"sonic_hw_collector_success", "sonic_crm_collector_success", "sonic_interface_collector_success"} {
+		registry.MustRegister(slowCollector{desc: prometheus.NewDesc(name, name, nil, nil), delay: delay})
+	}
+
+	start := time.Now()
+	families, err := registry.Gather()
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	if len(families) != 3 {
+		t.Errorf("unexpected number of metric families: %d", len(families))
+	}
+
+	// Serial collection would take three times the delay
+	if elapsed >= 2*delay {
+		t.Errorf("cold scrape took %v, collectors were not collected concurrently", elapsed)
+	}
+}
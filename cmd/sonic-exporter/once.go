@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// runOnce gathers the metrics of gatherer once and writes them to w in the text
+// exposition format. It returns the exit code of the one-shot mode, 1 if
+// gathering failed or a collector reports collector_success 0.
+func runOnce(gatherer prometheus.Gatherer, w io.Writer) int {
+	exitCode := 0
+
+	families, err := gatherer.Gather()
+	if err != nil {
+		// the families gathered despite the error are written anyway
+		fmt.Fprintf(w, "# gather failed: %s\n", strings.ReplaceAll(err.Error(), "\n", " "))
+		exitCode = 1
+	}
+
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(w, family); err != nil {
+			return 1
+		}
+
+		if strings.HasSuffix(family.GetName(), "collector_success") {
+			for _, metric := range family.GetMetric() {
+				if metric.GetGauge().GetValue() == 0 {
+					exitCode = 1
+				}
+			}
+		}
+	}
+
+	return exitCode
+}
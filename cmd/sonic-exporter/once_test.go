@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// successCollector reports a fixed collector_success
+type successCollector struct {
+	desc    *prometheus.Desc
+	success float64
+}
+
+func (c successCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c successCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, c.success)
+}
+
+// failingCollector fails every collect
+type failingCollector struct {
+	desc *prometheus.Desc
+}
+
+func (c failingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c failingCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.NewInvalidMetric(c.desc, errors.New("collect failed"))
+}
+
+func TestRunOnce(t *testing.T) {
+	newSuccess := func(name string, success float64) prometheus.Collector {
+		return successCollector{desc: prometheus.NewDesc(name, "Whether the collector succeeded", nil, nil), success: success}
+	}
+
+	tests := []struct {
+		name       string
+		collectors []prometheus.Collector
+		exitCode   int
+	}{
+		{
+			name:       "all collectors succeed",
+			collectors: []prometheus.Collector{newSuccess("sonic_hw_collector_success", 1), newSuccess("sonic_crm_collector_success", 1)},
+			exitCode:   0,
+		},
+		{
+			name:       "a collector fails",
+			collectors: []prometheus.Collector{newSuccess("sonic_hw_collector_success", 1), newSuccess("sonic_crm_collector_success", 0)},
+			exitCode:   1,
+		},
+		{
+			name:       "gathering fails",
+			collectors: []prometheus.Collector{newSuccess("sonic_hw_collector_success", 1), failingCollector{desc: prometheus.NewDesc("sonic_broken", "Broken", nil, nil)}},
+			exitCode:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(tt.collectors...)
+
+			var out bytes.Buffer
+			if exitCode := runOnce(registry, &out); exitCode != tt.exitCode {
+				t.Errorf("got exit code %d, want %d", exitCode, tt.exitCode)
+			}
+
+			if !strings.Contains(out.String(), "# TYPE sonic_hw_collector_success gauge\nsonic_hw_collector_success 1\n") {
+				t.Errorf("metrics were not written:\n%s", out.String())
+			}
+		})
+	}
+}
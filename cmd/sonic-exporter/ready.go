@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// readyPingTimeout bounds the redis PING of a readiness check
+	readyPingTimeout = 1 * time.Second
+	// readyPingDb is the database pinged to check that redis is reachable
+	readyPingDb = "STATE_DB"
+)
+
+// healthReporter is implemented by collectors reporting the outcome of their last scrape.
+type healthReporter interface {
+	Healthy() bool
+}
+
+// redisPinger is implemented by redis clients checked for reachability.
+type redisPinger interface {
+	Ping(ctx context.Context, dbName string) error
+}
+
+// readinessConfig controls how partial collector failures affect readiness.
+type readinessConfig struct {
+	// MaxFailing is the number of failing collectors tolerated as degraded, -1 tolerates any partial failure
+	MaxFailing int
+	// DegradedStatus is the status code returned while degraded
+	DegradedStatus int
+}
+
+// newHealthHandler reports liveness, the exporter is alive as long as it answers.
+func newHealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// newReadyHandler reports readiness from a PING of every redis client and the
+// health of collectors. Unreachable redis is never ready. All collectors
+// healthy is ready, all of them failing is never ready. Anything in between is degraded and answered with the configured status
+// code and an X-Exporter-Degraded header, unless more than MaxFailing collectors
+// are failing.
+func newReadyHandler(pingers []redisPinger, collectors []healthReporter, config readinessConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readyPingTimeout)
+		defer cancel()
+
+		for _, pinger := range pingers {
+			if err := pinger.Ping(ctx, readyPingDb); err != nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprintf(w, "redis unreachable: %v\n", err)
+				return
+			}
+		}
+
+		failing := 0
+		for _, collector := range collectors {
+			if !collector.Healthy() {
+				failing++
+			}
+		}
+
+		switch {
+		case failing == 0:
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintln(w, "ok")
+		case failing == len(collectors):
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, "not ready: all collectors failing")
+		case config.MaxFailing >= 0 && failing > config.MaxFailing:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "not ready: %d of %d collectors failing\n", failing, len(collectors))
+		default:
+			w.Header().Set("X-Exporter-Degraded", "true")
+			w.WriteHeader(config.DegradedStatus)
+			fmt.Fprintf(w, "degraded: %d of %d collectors failing\n", failing, len(collectors))
+		}
+	})
+}
+
+// newExporterUp returns a gauge summarizing the health of collectors for a single
+// alerting expression. Collectors run concurrently on a scrape, so it reflects
+// the outcome of the scrape each collector completed last.
+func newExporterUp(namespace string, collectors []healthReporter) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: prometheus.BuildFQName(namespace, "exporter", "up"),
+		Help: "Whether all collectors succeeded on their last scrape",
+	}, func() float64 {
+		for _, collector := range collectors {
+			if !collector.Healthy() {
+				return 0
+			}
+		}
+		return 1
+	})
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type stubHealthReporter bool
+
+func (s stubHealthReporter) Healthy() bool {
+	return bool(s)
+}
+
+func TestReadyHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		collectors []healthReporter
+		config     readinessConfig
+		status     int
+		degraded   bool
+	}{
+		{
+			name:       "all healthy",
+			collectors: []healthReporter{stubHealthReporter(true), stubHealthReporter(true), stubHealthReporter(true)},
+			config:     readinessConfig{MaxFailing: -1, DegradedStatus: http.StatusOK},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "degraded with header",
+			collectors: []healthReporter{stubHealthReporter(true), stubHealthReporter(false), stubHealthReporter(true)},
+			config:     readinessConfig{MaxFailing: -1, DegradedStatus: http.StatusOK},
+			status:     http.StatusOK,
+			degraded:   true,
+		},
+		{
+			name:       "degraded not ready",
+			collectors: []healthReporter{stubHealthReporter(true), stubHealthReporter(false), stubHealthReporter(true)},
+			config:     readinessConfig{MaxFailing: -1, DegradedStatus: http.StatusServiceUnavailable},
+			status:     http.StatusServiceUnavailable,
+			degraded:   true,
+		},
+		{
+			name:       "failing above threshold",
+			collectors: []healthReporter{stubHealthReporter(false), stubHealthReporter(false), stubHealthReporter(true)},
+			config:     readinessConfig{MaxFailing: 1, DegradedStatus: http.StatusOK},
+			status:     http.StatusServiceUnavailable,
+		},
+		{
+			name:       "all collectors failing",
+			collectors: []healthReporter{stubHealthReporter(false), stubHealthReporter(false), stubHealthReporter(false)},
+			config:     readinessConfig{MaxFailing: -1, DegradedStatus: http.StatusOK},
+			status:     http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			newReadyHandler(nil, tt.collectors, tt.config).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if recorder.Code != tt.status {
+				t.Errorf("got status %d, want %d", recorder.Code, tt.status)
+			}
+
+			if degraded := recorder.Header().Get("X-Exporter-Degraded") != ""; degraded != tt.degraded {
+				t.Errorf("got degraded header %v, want %v", degraded, tt.degraded)
+			}
+		})
+	}
+}
+
+func TestHealthAndReadyEndpoints(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", redisServer.Addr())
+
+	redisClient, err := redis.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create redis client: %v", err)
+	}
+	defer redisClient.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", newHealthHandler())
+	mux.Handle("/readyz", newReadyHandler([]redisPinger{redisClient}, []healthReporter{stubHealthReporter(true)},
+		readinessConfig{MaxFailing: -1, DegradedStatus: http.StatusOK}))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := server.Client().Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if status, _ := get("/healthz"); status != http.StatusOK {
+		t.Errorf("healthz: got status %d, want %d", status, http.StatusOK)
+	}
+	if status, _ := get("/readyz"); status != http.StatusOK {
+		t.Errorf("readyz with redis up: got status %d, want %d", status, http.StatusOK)
+	}
+
+	redisServer.Close()
+
+	if status, _ := get("/healthz"); status != http.StatusOK {
+		t.Errorf("healthz: got status %d, want %d", status, http.StatusOK)
+	}
+	status, body := get("/readyz")
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("readyz with redis down: got status %d, want %d", status, http.StatusServiceUnavailable)
+	}
+	if !strings.HasPrefix(body, "redis unreachable") {
+		t.Errorf("readyz with redis down: unexpected body %q", body)
+	}
+}
+
+func TestExporterUp(t *testing.T) {
+	tests := []struct {
+		name       string
+		collectors []healthReporter
+		expected   string
+	}{
+		{name: "all healthy", collectors: []healthReporter{stubHealthReporter(true), stubHealthReporter(true)}, expected: "1"},
+		{name: "one failing", collectors: []healthReporter{stubHealthReporter(true), stubHealthReporter(false)}, expected: "0"},
+		{name: "no collectors", expected: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := `
+				# HELP sonic_exporter_up Whether all collectors succeeded on their last scrape
+				# TYPE sonic_exporter_up gauge
+				sonic_exporter_up ` + tt.expected + `
+			`
+
+			if err := testutil.CollectAndCompare(newExporterUp("sonic", tt.collectors), strings.NewReader(expected)); err != nil {
+				t.Errorf("unexpected collecting result:\n%s", err)
+			}
+		})
+	}
+}
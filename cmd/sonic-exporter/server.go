@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/exporter-toolkit/web"
+)
+
+// instrumentMetricsHandler wraps the metrics handler with promhttp's request
+// counter and in-flight gauge and a histogram of the request durations
+func instrumentMetricsHandler(registerer prometheus.Registerer, handler http.Handler) http.Handler {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "promhttp_metric_handler_request_duration_seconds",
+		Help:    "Duration of the scrapes served by the metrics handler.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"code"})
+	registerer.MustRegister(duration)
+
+	return promhttp.InstrumentMetricHandler(registerer, promhttp.InstrumentHandlerDuration(duration, handler))
+}
+
+// newListenInfo returns a gauge relating the exporter to the addresses it listens
+// on. The addresses of a systemd socket are not known up front and not reported.
+func newListenInfo(namespace string, webConfig *web.FlagConfig) *prometheus.GaugeVec {
+	listenInfo := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: prometheus.BuildFQName(namespace, "exporter", "listen_info"),
+		Help: "Address the exporter listens on, value is always 1",
+	}, []string{"address"})
+
+	if webConfig.WebSystemdSocket != nil && *webConfig.WebSystemdSocket {
+		return listenInfo
+	}
+
+	if webConfig.WebListenAddresses != nil {
+		for _, address := range *webConfig.WebListenAddresses {
+			listenInfo.WithLabelValues(address).Set(1)
+		}
+	}
+
+	return listenInfo
+}
+
+// runServer runs listen until it fails or ctx is cancelled. On cancellation srv
+// is shut down, waiting up to timeout for in-flight scrapes to complete.
+func runServer(ctx context.Context, srv *http.Server, listen func() error, timeout time.Duration, logger *slog.Logger) error {
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- listen()
+	}()
+
+	select {
+	case err := <-listenErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.InfoContext(context.Background(), "Shutting down HTTP server", "timeout", timeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-listenErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	logger.InfoContext(context.Background(), "HTTP server stopped")
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/exporter-toolkit/web"
+)
+
+func TestRunServerShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	requestStarted := make(chan struct{})
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(requestStarted)
+			// in-flight scrape that completes during shutdown
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const timeout = 2 * time.Second
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- runServer(ctx, srv, func() error { return srv.Serve(listener) }, timeout, promslog.New(&promslog.Config{}))
+	}()
+
+	responseStatus := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responseStatus <- 0
+			return
+		}
+		resp.Body.Close()
+		responseStatus <- resp.StatusCode
+	}()
+
+	<-requestStarted
+	cancel()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			t.Errorf("unexpected shutdown error: %v", err)
+		}
+	case <-time.After(timeout):
+		t.Fatalf("server did not shut down within %v", timeout)
+	}
+
+	if status := <-responseStatus; status != http.StatusOK {
+		t.Errorf("in-flight request was not completed: status %d", status)
+	}
+}
+
+func TestListenInfo(t *testing.T) {
+	addresses := []string{"0.0.0.0:9101", "[::]:9101"}
+	systemdSocket := false
+
+	listenInfo := newListenInfo("sonic", &web.FlagConfig{WebListenAddresses: &addresses, WebSystemdSocket: &systemdSocket})
+
+	if err := testutil.CollectAndCompare(listenInfo, strings.NewReader(`
+		# HELP sonic_exporter_listen_info Address the exporter listens on, value is always 1
+		# TYPE sonic_exporter_listen_info gauge
+		sonic_exporter_listen_info{address="0.0.0.0:9101"} 1
+		sonic_exporter_listen_info{address="[::]:9101"} 1
+	`)); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	systemdSocket = true
+	if count := testutil.CollectAndCount(newListenInfo("sonic", &web.FlagConfig{WebListenAddresses: &addresses, WebSystemdSocket: &systemdSocket})); count != 0 {
+		t.Errorf("expected no addresses with a systemd socket, got %d", count)
+	}
+}
+
+func TestInstrumentMetricsHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	server := httptest.NewServer(instrumentMetricsHandler(registry, newMetricsHandler(registry, promhttp.HandlerOpts{})))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := server.Client().Get(server.URL)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP promhttp_metric_handler_requests_total Total number of scrapes by HTTP status code.
+		# TYPE promhttp_metric_handler_requests_total counter
+		promhttp_metric_handler_requests_total{code="200"} 2
+		promhttp_metric_handler_requests_total{code="500"} 0
+		promhttp_metric_handler_requests_total{code="503"} 0
+	`), "promhttp_metric_handler_requests_total"); err != nil {
+		t.Errorf("unexpected request counter:\n%s", err)
+	}
+
+	count, err := testutil.GatherAndCount(registry, "promhttp_metric_handler_request_duration_seconds")
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected a request duration histogram for code 200, got %d series", count)
+	}
+}
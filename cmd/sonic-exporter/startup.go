@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// startupCheckInterval is the delay between connectivity checks while waiting for redis
+const startupCheckInterval = 1 * time.Second
+
+// startupCheckDbs are the databases the collectors read from
+var startupCheckDbs = []string{"APPL_DB", "ASIC_DB", "COUNTERS_DB", "CONFIG_DB", "STATE_DB"}
+
+// checkRedis pings every database of startupCheckDbs through each of pingers.
+// Failed checks are repeated every startupCheckInterval until wait elapsed, a
+// wait of 0 fails on the first unreachable database. The returned error names
+// the database that couldn't be reached.
+func checkRedis(ctx context.Context, logger *slog.Logger, pingers []redisPinger, wait time.Duration) error {
+	deadline := time.Now().Add(wait)
+
+	for {
+		err := pingDbs(ctx, pingers)
+		if err == nil {
+			return nil
+		}
+		if !time.Now().Add(startupCheckInterval).Before(deadline) {
+			return err
+		}
+
+		logger.WarnContext(ctx, "Waiting for redis", "err", err)
+		select {
+		case <-time.After(startupCheckInterval):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// pingDbs returns the error of the first database of startupCheckDbs not answering a PING
+func pingDbs(ctx context.Context, pingers []redisPinger) error {
+	for _, pinger := range pingers {
+		for _, dbName := range startupCheckDbs {
+			pingCtx, cancel := context.WithTimeout(ctx, readyPingTimeout)
+			err := pinger.Ping(pingCtx, dbName)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("redis of %s unreachable: %w", dbName, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/promslog"
+)
+
+// stubPinger fails the PINGs of the databases in down
+type stubPinger struct {
+	down  map[string]bool
+	pings int
+}
+
+func (s *stubPinger) Ping(_ context.Context, dbName string) error {
+	s.pings++
+	if s.down[dbName] {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestCheckRedis(t *testing.T) {
+	logger := promslog.New(&promslog.Config{})
+
+	t.Run("reachable", func(t *testing.T) {
+		pingers := []redisPinger{&stubPinger{}, &stubPinger{}}
+		if err := checkRedis(context.Background(), logger, pingers, 0); err != nil {
+			t.Errorf("expected reachable redis to pass, got %v", err)
+		}
+		for _, pinger := range pingers {
+			if pings := pinger.(*stubPinger).pings; pings != len(startupCheckDbs) {
+				t.Errorf("expected %d pings, got %d", len(startupCheckDbs), pings)
+			}
+		}
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		pinger := &stubPinger{down: map[string]bool{"COUNTERS_DB": true}}
+		start := time.Now()
+		err := checkRedis(context.Background(), logger, []redisPinger{&stubPinger{}, pinger}, 0)
+		if err == nil || !strings.Contains(err.Error(), "COUNTERS_DB") {
+			t.Errorf("expected an error naming COUNTERS_DB, got %v", err)
+		}
+		if time.Since(start) >= startupCheckInterval {
+			t.Errorf("expected the check to fail without retrying")
+		}
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		pinger := &stubPinger{down: map[string]bool{"STATE_DB": true}}
+		if err := checkRedis(context.Background(), logger, []redisPinger{pinger}, 2*startupCheckInterval+startupCheckInterval/2); err == nil {
+			t.Errorf("expected unreachable redis to fail after waiting")
+		}
+		// checked at 0s, 1s and 2s
+		if checks := pinger.pings / len(startupCheckDbs); checks != 3 {
+			t.Errorf("expected 3 checks, got %d", checks)
+		}
+	})
+}
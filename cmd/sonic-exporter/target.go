@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mwennrich/sonic-exporter/internal/collector"
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// errTooManyTargets is returned when all cached targets are being scraped and
+// none can be evicted for a new one
+var errTooManyTargets = errors.New("too many targets scraped at once")
+
+// scrapeTarget holds the redis client and collectors of a remote device
+type scrapeTarget struct {
+	redisClient *redis.Client
+	gatherer    prometheus.Gatherer
+	// lastUsed is the end of the last scrape, active the number of running scrapes
+	lastUsed time.Time
+	active   int
+}
+
+// targetOptions restrict the remote devices scraped through /scrape
+type targetOptions struct {
+	// allowed are the addresses that may be scraped
+	allowed []string
+	// maxTargets is the number of targets whose client and collectors are kept
+	maxTargets int
+	// idleTimeout is how long the client and collectors of an unscraped target are kept
+	idleTimeout time.Duration
+}
+
+// targetHandler scrapes remote SONiC devices given by the target parameter,
+// following the multi-target pattern of the blackbox and snmp exporters. Only
+// allowed targets are scraped. The client and collectors of a target are kept,
+// so their caches are reused by following scrapes, until the target wasn't
+// scraped for the idle timeout or the least recently scraped target makes room
+// for a new one.
+type targetHandler struct {
+	targets    map[string]*scrapeTarget
+	logger     *slog.Logger
+	config     collector.Config
+	fileConfig fileConfig
+	redisOpts  redisOptions
+	// add the hostname the target reports as label
+	hostnameLabel bool
+	targetOpts    targetOptions
+	opts          promhttp.HandlerOpts
+	mu            sync.Mutex
+}
+
+func newTargetHandler(logger *slog.Logger, config collector.Config, fileConfig fileConfig, redisOpts redisOptions, hostnameLabel bool, targetOpts targetOptions, opts promhttp.HandlerOpts) *targetHandler {
+	return &targetHandler{
+		targets:       make(map[string]*scrapeTarget),
+		logger:        logger,
+		config:        config,
+		fileConfig:    fileConfig,
+		redisOpts:     redisOpts,
+		hostnameLabel: hostnameLabel,
+		targetOpts:    targetOpts,
+		opts:          opts,
+	}
+}
+
+func (h *targetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	targetAddress := r.URL.Query().Get("target")
+	if targetAddress == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+
+	if !slices.Contains(h.targetOpts.allowed, targetAddress) {
+		http.Error(w, "target is not allowed", http.StatusForbidden)
+		return
+	}
+
+	target, err := h.target(targetAddress)
+	if errors.Is(err, errTooManyTargets) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Error creating redis client", "target", targetAddress, "err", err)
+		http.Error(w, "error creating redis client: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer h.release(target)
+
+	newMetricsHandler(target.gatherer, h.opts).ServeHTTP(w, r)
+}
+
+// target returns the scrape target of address, creating it on first use. The
+// target is kept until it is released.
+func (h *targetHandler) target(address string) (*scrapeTarget, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.evictIdle(time.Now())
+
+	if target, ok := h.targets[address]; ok {
+		target.active++
+		return target, nil
+	}
+
+	if h.targetOpts.maxTargets > 0 && len(h.targets) >= h.targetOpts.maxTargets && !h.evictLeastRecentlyUsed() {
+		return nil, errTooManyTargets
+	}
+
+	redisClient, err := redis.NewTargetClient(address)
+	if err != nil {
+		return nil, err
+	}
+	h.redisOpts.apply(redisClient)
+
+	// Files of the local host don't describe the target, the image version is
+	// left empty and no uptime is reported
+	config := h.config
+	config.VersionFile = ""
+	config.UptimeFile = ""
+
+	registry := newCollectorRegistry(prometheus.Gatherers{})
+	registerer := registry.registerer(h.fileConfig.Labels).with(prometheus.Labels{"sonic_scrape_target": address})
+
+	registerCollectors(registerer, hostCollectors, h.logger, redisClient, config, h.fileConfig)
+	registerCollectors(registerer, asicCollectors, h.logger, redisClient, config, h.fileConfig)
+
+	var gatherer prometheus.Gatherer = registry
+	if h.hostnameLabel {
+		gatherer = newHostnameGatherer(registry, redisClient, h.logger)
+	}
+
+	target := &scrapeTarget{redisClient: redisClient, gatherer: gatherer, active: 1}
+	h.targets[address] = target
+
+	return target, nil
+}
+
+// release marks a scrape of target as done
+func (h *targetHandler) release(target *scrapeTarget) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	target.active--
+	target.lastUsed = time.Now()
+}
+
+// evictIdle closes and removes the targets not scraped for the idle timeout
+func (h *targetHandler) evictIdle(now time.Time) {
+	if h.targetOpts.idleTimeout <= 0 {
+		return
+	}
+
+	for address, target := range h.targets {
+		if target.active == 0 && now.Sub(target.lastUsed) >= h.targetOpts.idleTimeout {
+			h.evict(address)
+		}
+	}
+}
+
+// evictLeastRecentlyUsed closes and removes the least recently scraped target
+// not being scraped. It returns false if all targets are being scraped.
+func (h *targetHandler) evictLeastRecentlyUsed() bool {
+	var oldest string
+	for address, target := range h.targets {
+		if target.active > 0 {
+			continue
+		}
+		if oldest == "" || target.lastUsed.Before(h.targets[oldest].lastUsed) {
+			oldest = address
+		}
+	}
+
+	if oldest == "" {
+		return false
+	}
+	h.evict(oldest)
+	return true
+}
+
+func (h *targetHandler) evict(address string) {
+	h.logger.DebugContext(context.Background(), "Evicting scrape target", "target", address)
+	h.targets[address].redisClient.Close()
+	delete(h.targets, address)
+}
+
+// Close closes the redis clients of all targets
+func (h *targetHandler) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for address, target := range h.targets {
+		target.redisClient.Close()
+		delete(h.targets, address)
+	}
+}
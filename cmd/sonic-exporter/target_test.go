@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mwennrich/sonic-exporter/internal/collector"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/promslog"
+)
+
+func TestTargetHandler(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	redisServer.DB(6).HSet("CHASSIS_INFO|chassis 1", "psu_num", "2", "serial", "S1", "model", "M1")
+
+	// an address nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	unreachable := listener.Addr().String()
+	listener.Close()
+
+	targetOpts := targetOptions{allowed: []string{redisServer.Addr(), unreachable}}
+	targets := newTargetHandler(promslog.New(&promslog.Config{}), collector.Config{}, fileConfig{}, redisOptions{scan: true}, false, targetOpts, promhttp.HandlerOpts{})
+	defer targets.Close()
+
+	server := httptest.NewServer(targets)
+	defer server.Close()
+
+	get := func(query string) (int, string) {
+		resp, err := server.Client().Get(server.URL + "/scrape" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if status, _ := get(""); status != http.StatusBadRequest {
+		t.Errorf("missing target: got status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	if status, _ := get("?target=127.0.0.1:1"); status != http.StatusForbidden {
+		t.Errorf("target not allowed: got status %d, want %d", status, http.StatusForbidden)
+	}
+	if len(targets.targets) != 0 {
+		t.Errorf("target not allowed: expected no client to be created, got %d targets", len(targets.targets))
+	}
+
+	status, body := get("?target=" + redisServer.Addr())
+	if status != http.StatusOK {
+		t.Fatalf("reachable target: got status %d", status)
+	}
+	if !strings.Contains(body, `sonic_hw_collector_success{sonic_scrape_target="`+redisServer.Addr()+`"} 1`) {
+		t.Errorf("reachable target: collector did not succeed:\n%s", body)
+	}
+	if !strings.Contains(body, `sonic_hw_chassis_info{model="M1",name="chassis 1",psu_num="2",serial="S1",sonic_scrape_target="`+redisServer.Addr()+`"} 1`) {
+		t.Errorf("reachable target: metrics of the target are missing:\n%s", body)
+	}
+
+	status, body = get("?target=" + unreachable)
+	if status != http.StatusOK {
+		t.Fatalf("unreachable target: got status %d", status)
+	}
+	if !strings.Contains(body, `sonic_hw_collector_success{sonic_scrape_target="`+unreachable+`"} 0`) {
+		t.Errorf("unreachable target: collector should fail:\n%s", body)
+	}
+	if strings.Contains(body, redisServer.Addr()) {
+		t.Errorf("unreachable target: response contains metrics of another target")
+	}
+}
+
+func TestTargetHandlerEviction(t *testing.T) {
+	first := miniredis.RunT(t)
+	second := miniredis.RunT(t)
+
+	targetOpts := targetOptions{allowed: []string{first.Addr(), second.Addr()}, maxTargets: 1, idleTimeout: time.Minute}
+	targets := newTargetHandler(promslog.New(&promslog.Config{}), collector.Config{}, fileConfig{}, redisOptions{scan: true}, false, targetOpts, promhttp.HandlerOpts{})
+	defer targets.Close()
+
+	scrape := func(address string) *scrapeTarget {
+		target, err := targets.target(address)
+		if err != nil {
+			t.Fatalf("failed to get target %s: %v", address, err)
+		}
+		return target
+	}
+
+	// a target being scraped is not evicted
+	firstTarget := scrape(first.Addr())
+	if err := firstTarget.redisClient.Ping(context.Background(), "STATE_DB"); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+	if _, err := targets.target(second.Addr()); !errors.Is(err, errTooManyTargets) {
+		t.Errorf("expected %v while the only target is scraped, got %v", errTooManyTargets, err)
+	}
+	targets.release(firstTarget)
+
+	// the least recently used target makes room
+	targets.release(scrape(second.Addr()))
+	if _, ok := targets.targets[first.Addr()]; ok || len(targets.targets) != 1 {
+		t.Errorf("expected %s to be evicted, got %d targets", first.Addr(), len(targets.targets))
+	}
+	// the server notices the closed connection asynchronously
+	for deadline := time.Now().Add(time.Second); first.CurrentConnectionCount() > 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count := first.CurrentConnectionCount(); count != 0 {
+		t.Errorf("expected the redis client of the evicted target to be closed, %d connections open", count)
+	}
+
+	// idle targets are evicted on the next scrape
+	targets.targets[second.Addr()].lastUsed = time.Now().Add(-time.Minute)
+	targets.release(scrape(first.Addr()))
+	if _, ok := targets.targets[second.Addr()]; ok {
+		t.Errorf("expected idle target %s to be evicted", second.Addr())
+	}
+}
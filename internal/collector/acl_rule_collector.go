@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type aclRuleCollector struct {
+	*scrapeCache
+	aclRulePackets *prometheus.Desc
+	aclRuleBytes   *prometheus.Desc
+	redisClient    *redis.Client
+}
+
+func NewAclRuleCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *aclRuleCollector {
+	const subsystem = "acl_rule"
+	namespace := config.namespace()
+
+	collector := &aclRuleCollector{
+		aclRulePackets: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "packets_total"),
+			"Number of packets matched by an ACL rule", []string{"table", "rule"}, nil),
+		aclRuleBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "bytes_total"),
+			"Number of bytes matched by an ACL rule", []string{"table", "rule"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "acl rule", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *aclRuleCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.aclRulePackets
+	ch <- collector.aclRuleBytes
+	collector.describeScrape(ch)
+}
+
+func (collector *aclRuleCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	aclRuleCountersMetrics, err := collector.collectAclRuleCounters(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("acl rule counters collection failed: %w", err)
+	}
+	metrics = append(metrics, aclRuleCountersMetrics...)
+
+	return metrics, nil
+}
+
+// collectAclRuleCounters reads the counters of the ACL rules configured in
+// CONFIG_DB (ACL_RULE|<table>|<rule>). The counter oid of a rule is looked up
+// in ACL_COUNTER_RULE_MAP by "<table>:<rule>", rules without counter are
+// skipped.
+func (collector *aclRuleCollector) collectAclRuleCounters(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const aclRuleKeyPattern string = "ACL_RULE|*"
+
+	aclRuleCounters := map[*prometheus.Desc]string{
+		collector.aclRulePackets: "SAI_ACL_COUNTER_ATTR_PACKETS",
+		collector.aclRuleBytes:   "SAI_ACL_COUNTER_ATTR_BYTES",
+	}
+
+	aclRuleKeys, err := redisClient.KeysFromDb(ctx, "CONFIG_DB", aclRuleKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	aclRules, err := resolveNameMap(ctx, redisClient, "ACL_COUNTER_RULE_MAP", "COUNTERS", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, aclRuleKey := range aclRuleKeys {
+		keyParts := strings.SplitN(aclRuleKey, "|", 3)
+		if len(keyParts) != 3 {
+			continue
+		}
+		tableName, ruleName := keyParts[1], keyParts[2]
+
+		aclRule, ok := aclRules[tableName+":"+ruleName]
+		if !ok {
+			continue
+		}
+
+		for desc, field := range aclRuleCounters {
+			value, ok := aclRule.fields[field]
+			if !ok {
+				continue
+			}
+
+			parsedValue, err := parseFloat(value)
+			if err != nil {
+				continue
+			}
+
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				desc, prometheus.CounterValue, parsedValue, tableName, ruleName,
+			))
+		}
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type bufferCollector struct {
+	*scrapeCache
+	bufferPoolWatermark          *prometheus.Desc
+	bufferPriorityGroupWatermark *prometheus.Desc
+	redisClient                  *redis.Client
+}
+
+func NewBufferCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *bufferCollector {
+	const subsystem = "buffer"
+	namespace := config.namespace()
+
+	collector := &bufferCollector{
+		bufferPoolWatermark: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "pool_watermark_bytes"),
+			"Peak shared buffer pool occupancy since the persistent watermark was last cleared", []string{"pool"}, nil),
+		bufferPriorityGroupWatermark: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "priority_group_watermark_bytes"),
+			"Peak shared buffer occupancy of an ingress priority group since the persistent watermark was last cleared", []string{"device", "pg"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "buffer", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *bufferCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.bufferPoolWatermark
+	ch <- collector.bufferPriorityGroupWatermark
+	collector.describeScrape(ch)
+}
+
+func (collector *bufferCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	poolWatermarksMetrics, err := collector.collectPoolWatermarks(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("buffer pool watermark collection failed: %w", err)
+	}
+	metrics = append(metrics, poolWatermarksMetrics...)
+
+	priorityGroupWatermarksMetrics, err := collector.collectPriorityGroupWatermarks(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("priority group watermark collection failed: %w", err)
+	}
+	metrics = append(metrics, priorityGroupWatermarksMetrics...)
+
+	if collector.config.WatermarkClear {
+		collector.clearWatermarks(ctx, redisClient)
+	}
+
+	return metrics, nil
+}
+
+// clearWatermarks asks orchagent to clear the persistent buffer pool and priority
+// group watermarks, so the next scrape reports the peaks since this one. Failing
+// to clear doesn't fail the scrape, its watermarks have been read already.
+func (collector *bufferCollector) clearWatermarks(ctx context.Context, redisClient *redis.Client) {
+	for _, watermarkType := range []string{"BUFFER_POOL", "PG_SHARED"} {
+		// the request watermarkstat -c sends
+		request := fmt.Sprintf(`["PERSISTENT","%s"]`, watermarkType)
+		if err := redisClient.PublishToDb(ctx, "APPL_DB", "WATERMARK_CLEAR_REQUEST", request); err != nil {
+			collector.logger.ErrorContext(ctx, "Clearing watermarks failed", "type", watermarkType, "err", err)
+		}
+	}
+}
+
+// collectPoolWatermarks resolves buffer pools through COUNTERS_BUFFER_POOL_NAME_MAP
+// and reads their persistent watermarks. Watermarks that cannot be parsed are skipped.
+func (collector *bufferCollector) collectPoolWatermarks(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	pools, err := resolveNameMap(ctx, redisClient, "COUNTERS_BUFFER_POOL_NAME_MAP", "PERSISTENT_WATERMARKS", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for poolName, pool := range pools {
+		watermark, ok := pool.fields["SAI_BUFFER_POOL_STAT_WATERMARK_BYTES"]
+		if !ok {
+			continue
+		}
+
+		parsedValue, err := parseFloat(watermark)
+		if err != nil {
+			continue
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.bufferPoolWatermark, prometheus.GaugeValue, parsedValue, poolName,
+		))
+	}
+
+	return metrics, nil
+}
+
+// collectPriorityGroupWatermarks resolves ingress priority groups through
+// COUNTERS_PG_NAME_MAP ("Ethernet0:3" -> priority group oid) the same way queues
+// are resolved and reads their persistent shared watermarks. Watermarks that
+// cannot be parsed are skipped.
+func (collector *bufferCollector) collectPriorityGroupWatermarks(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	priorityGroups, err := resolveNameMap(ctx, redisClient, "COUNTERS_PG_NAME_MAP", "PERSISTENT_WATERMARKS", func(_, index string) bool {
+		return index != ""
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, priorityGroup := range priorityGroups {
+		portName, priorityGroupIndex := priorityGroup.port, priorityGroup.index
+
+		watermark, ok := priorityGroup.fields["SAI_INGRESS_PRIORITY_GROUP_STAT_SHARED_WATERMARK_BYTES"]
+		if !ok {
+			continue
+		}
+
+		parsedValue, err := parseFloat(watermark)
+		if err != nil {
+			continue
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.bufferPriorityGroupWatermark, prometheus.GaugeValue, parsedValue, portName, priorityGroupIndex,
+		))
+	}
+
+	return metrics, nil
+}
@@ -3,18 +3,36 @@ package collector
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
+	"log/slog"
+	"net"
 	"os"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/promslog"
 )
 
+var (
+	redisServer *miniredis.Miniredis
+	// redisClient is shared by the collectors under test
+	redisClient *redis.Client
+	testConfig  = Config{CacheDuration: 15 * time.Second}
+)
+
 type redisDatabase struct {
 	DbId string                       `json:"id"`
 	Data map[string]map[string]string `json:"data"`
@@ -24,6 +42,7 @@ func populateRedisData() error {
 	var ctx = context.Background()
 
 	files := []string{
+		"../../fixtures/test/asic_db_data.json",
 		"../../fixtures/test/counters_db_data.json",
 		"../../fixtures/test/config_db_data.json",
 		"../../fixtures/test/appl_db_data.json",
@@ -44,6 +63,7 @@ func pushDataFromFile(ctx context.Context, fileName string) error {
 	var database redisDatabase
 
 	redisClient, _ := redis.NewClient()
+	defer redisClient.Close()
 
 	file, _ := os.Open(fileName)
 	defer file.Close()
@@ -68,22 +88,32 @@ func pushDataFromFile(ctx context.Context, fileName string) error {
 }
 
 func TestMain(m *testing.M) {
-	s, err := miniredis.Run()
+	var err error
+
+	redisServer, err = miniredis.Run()
 	if err != nil {
 		log.Printf("failed to start redis: %v", err)
 		os.Exit(1)
 	}
 
-	os.Setenv("REDIS_ADDRESS", s.Addr())
+	os.Setenv("REDIS_ADDRESS", redisServer.Addr())
 	err = populateRedisData()
 	if err != nil {
 		log.Printf("failed to populate redis data: %v", err)
 		os.Exit(1)
 	}
 
+	redisClient, err = redis.NewClient()
+	if err != nil {
+		log.Printf("failed to create redis client: %v", err)
+		os.Exit(1)
+	}
+
 	exitCode := m.Run()
 
-	s.Close()
+	redisClient.Close()
+
+	redisServer.Close()
 	os.Unsetenv("REDIS_ADDRESS")
 	os.Exit(exitCode)
 }
@@ -92,7 +122,7 @@ func TestInterfaceCollector(t *testing.T) {
 	promslogConfig := &promslog.Config{}
 	logger := promslog.New(promslogConfig)
 
-	interfaceCollector := NewInterfaceCollector(logger)
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
 
 	problems, err := testutil.CollectAndLint(interfaceCollector)
 	if err != nil {
@@ -126,7 +156,7 @@ func TestHwCollector(t *testing.T) {
 	promslogConfig := &promslog.Config{}
 	logger := promslog.New(promslogConfig)
 
-	hwCollector := NewHwCollector(logger)
+	hwCollector := NewHwCollector(logger, redisClient, testConfig)
 
 	problems, err := testutil.CollectAndLint(hwCollector)
 	if err != nil {
@@ -160,7 +190,7 @@ func TestCrmCollector(t *testing.T) {
 	promslogConfig := &promslog.Config{}
 	logger := promslog.New(promslogConfig)
 
-	crmCollector := NewCrmCollector(logger)
+	crmCollector := NewCrmCollector(logger, redisClient, testConfig)
 
 	problems, err := testutil.CollectAndLint(crmCollector)
 	if err != nil {
@@ -189,3 +219,3140 @@ func TestCrmCollector(t *testing.T) {
 		t.Errorf("unexpected collecting result:\n%s", err)
 	}
 }
+
+func TestInterfacePfcPauseDuration(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_interface_pfc_rx_pause_duration_seconds_total Time an interface priority was paused by received PFC frames
+		# TYPE sonic_interface_pfc_rx_pause_duration_seconds_total counter
+	`
+
+	expected := `
+		sonic_interface_pfc_rx_pause_duration_seconds_total{device="Ethernet0",priority="3"} 1.5
+		sonic_interface_pfc_rx_pause_duration_seconds_total{device="Ethernet0",priority="4"} 0.25
+	`
+
+	if err := testutil.CollectAndCompare(interfaceCollector, strings.NewReader(metadata+expected),
+		"sonic_interface_pfc_rx_pause_duration_seconds_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestInterfacePfcPackets(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_interface_pfc_rx_packets_total Number of PFC frames received on an interface priority
+		# TYPE sonic_interface_pfc_rx_packets_total counter
+		# HELP sonic_interface_pfc_tx_packets_total Number of PFC frames transmitted on an interface priority
+		# TYPE sonic_interface_pfc_tx_packets_total counter
+	`
+
+	// Ethernet0 counts all priorities, Ethernet39 only priority 3
+	expected := `
+		sonic_interface_pfc_rx_packets_total{device="Ethernet0",priority="0"} 10
+		sonic_interface_pfc_rx_packets_total{device="Ethernet0",priority="1"} 20
+		sonic_interface_pfc_rx_packets_total{device="Ethernet0",priority="2"} 30
+		sonic_interface_pfc_rx_packets_total{device="Ethernet0",priority="3"} 40
+		sonic_interface_pfc_rx_packets_total{device="Ethernet0",priority="4"} 50
+		sonic_interface_pfc_rx_packets_total{device="Ethernet0",priority="5"} 60
+		sonic_interface_pfc_rx_packets_total{device="Ethernet0",priority="6"} 70
+		sonic_interface_pfc_rx_packets_total{device="Ethernet0",priority="7"} 80
+		sonic_interface_pfc_rx_packets_total{device="Ethernet39",priority="3"} 5
+		sonic_interface_pfc_tx_packets_total{device="Ethernet0",priority="0"} 100
+		sonic_interface_pfc_tx_packets_total{device="Ethernet0",priority="1"} 200
+		sonic_interface_pfc_tx_packets_total{device="Ethernet0",priority="2"} 300
+		sonic_interface_pfc_tx_packets_total{device="Ethernet0",priority="3"} 400
+		sonic_interface_pfc_tx_packets_total{device="Ethernet0",priority="4"} 500
+		sonic_interface_pfc_tx_packets_total{device="Ethernet0",priority="5"} 600
+		sonic_interface_pfc_tx_packets_total{device="Ethernet0",priority="6"} 700
+		sonic_interface_pfc_tx_packets_total{device="Ethernet0",priority="7"} 800
+		sonic_interface_pfc_tx_packets_total{device="Ethernet39",priority="3"} 0
+	`
+
+	if err := testutil.CollectAndCompare(interfaceCollector, strings.NewReader(metadata+expected),
+		"sonic_interface_pfc_rx_packets_total", "sonic_interface_pfc_tx_packets_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestInterfaceFecCounters(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_interface_fec_corrected_bit_errors_total Number of bit errors corrected by FEC on an interface
+		# TYPE sonic_interface_fec_corrected_bit_errors_total counter
+		# HELP sonic_interface_fec_uncorrectable_frames_total Number of received frames FEC failed to correct on an interface
+		# TYPE sonic_interface_fec_uncorrectable_frames_total counter
+	`
+
+	// Ethernet39 has no corrected bits counter, Ethernet72 and Ethernet76 no FEC counters
+	expected := `
+		sonic_interface_fec_corrected_bit_errors_total{device="Ethernet0"} 1024
+		sonic_interface_fec_uncorrectable_frames_total{device="Ethernet0"} 3
+		sonic_interface_fec_uncorrectable_frames_total{device="Ethernet39"} 0
+	`
+
+	if err := testutil.CollectAndCompare(interfaceCollector, strings.NewReader(metadata+expected),
+		"sonic_interface_fec_corrected_bit_errors_total", "sonic_interface_fec_uncorrectable_frames_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestInterfaceErrorAndDiscardCounters(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	counters := map[string]string{
+		"SAI_PORT_STAT_IF_IN_ERRORS":    "11",
+		"SAI_PORT_STAT_IF_IN_DISCARDS":  "12",
+		"SAI_PORT_STAT_IN_DROPPED_PKTS": "13",
+		"SAI_PORT_STAT_PAUSE_RX_PKTS":   "14",
+		"SAI_PORT_STAT_IF_OUT_ERRORS":   "21",
+		"SAI_PORT_STAT_IF_OUT_DISCARDS": "22",
+		"SAI_PORT_STAT_PAUSE_TX_PKTS":   "24",
+	}
+
+	metrics, err := interfaceCollector.collectInterfaceErrCounters("Ethernet0", counters)
+	if err != nil {
+		t.Fatalf("failed to collect error counters: %v", err)
+	}
+
+	expected := map[string]float64{
+		"receive_errs_total/error":    11,
+		"receive_errs_total/discard":  12,
+		"transmit_errs_total/error":   21,
+		"transmit_errs_total/discard": 22,
+	}
+
+	for _, metric := range metrics {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+
+		direction := "receive_errs_total"
+		if metric.Desc() == interfaceCollector.interfaceTransmitErrs {
+			direction = "transmit_errs_total"
+		}
+
+		var errType string
+		for _, label := range m.GetLabel() {
+			if label.GetName() == "type" {
+				errType = label.GetValue()
+			}
+		}
+
+		value, ok := expected[direction+"/"+errType]
+		if !ok {
+			continue
+		}
+		delete(expected, direction+"/"+errType)
+
+		if m.GetCounter() == nil {
+			t.Errorf("%s/%s is not a counter", direction, errType)
+			continue
+		}
+		if m.GetCounter().GetValue() != value {
+			t.Errorf("%s/%s: got %v, want %v", direction, errType, m.GetCounter().GetValue(), value)
+		}
+	}
+
+	for missing := range expected {
+		t.Errorf("%s not collected", missing)
+	}
+}
+
+func TestInterfaceStatusAndSpeed(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	redisServer.DB(4).HSet("PORT|Ethernet120", "admin_status", "up", "alias", "hundredGigE31", "index", "31", "mtu", "9100", "speed", "100000")
+	redisServer.DB(0).HSet("PORT_TABLE:Ethernet120", "admin_status", "up", "oper_status", "down")
+	// PORT_TABLE entries of ports that have not come up yet lack oper_status
+	redisServer.DB(0).HSet("PORT_TABLE:Ethernet124", "admin_status", "down")
+	defer redisServer.DB(4).Del("PORT|Ethernet120")
+	defer redisServer.DB(0).Del("PORT_TABLE:Ethernet120")
+	defer redisServer.DB(0).Del("PORT_TABLE:Ethernet124")
+
+	gaugeValues := func(metrics []prometheus.Metric) map[*prometheus.Desc]float64 {
+		values := make(map[*prometheus.Desc]float64)
+		for _, metric := range metrics {
+			var m dto.Metric
+			if err := metric.Write(&m); err != nil {
+				t.Fatalf("failed to write metric: %v", err)
+			}
+			values[metric.Desc()] = m.GetGauge().GetValue()
+		}
+		return values
+	}
+
+	ctx := context.Background()
+
+	configInfo, err := redisClient.HgetAllFromDb(ctx, "CONFIG_DB", "PORT|Ethernet120")
+	if err != nil {
+		t.Fatalf("failed to read config info: %v", err)
+	}
+
+	configMetrics, err := interfaceCollector.collectInterfaceConfigInfo("Ethernet120", configInfo, nil)
+	if err != nil {
+		t.Fatalf("failed to collect config info: %v", err)
+	}
+	// 100000 Mbps
+	if speed := gaugeValues(configMetrics)[interfaceCollector.interfaceSpeed]; speed != 12.5e9 {
+		t.Errorf("got speed %v bytes, want 12.5e9", speed)
+	}
+
+	tests := []struct {
+		interfaceName string
+		adminStatus   float64
+		operStatus    float64
+	}{
+		{interfaceName: "Ethernet120", adminStatus: 1, operStatus: 0},
+		{interfaceName: "Ethernet124", adminStatus: 0, operStatus: 0},
+		{interfaceName: "Ethernet80", adminStatus: 1, operStatus: 1},
+	}
+
+	for _, tt := range tests {
+		portInfo, err := redisClient.HgetAllFromDb(ctx, "APPL_DB", "PORT_TABLE:"+tt.interfaceName)
+		if err != nil {
+			t.Fatalf("%s: failed to read operation info: %v", tt.interfaceName, err)
+		}
+
+		operationMetrics := interfaceCollector.collectInterfaceOperationInfo(tt.interfaceName, portInfo)
+
+		values := gaugeValues(operationMetrics)
+		if values[interfaceCollector.interfaceAdminStatus] != tt.adminStatus {
+			t.Errorf("%s: got admin status %v, want %v", tt.interfaceName, values[interfaceCollector.interfaceAdminStatus], tt.adminStatus)
+		}
+		if values[interfaceCollector.interfaceOperationslStatus] != tt.operStatus {
+			t.Errorf("%s: got operational status %v, want %v", tt.interfaceName, values[interfaceCollector.interfaceOperationslStatus], tt.operStatus)
+		}
+	}
+}
+
+// metricsCollector collects a fixed set of metrics, e.g. the result of a single collection step
+type metricsCollector []prometheus.Metric
+
+func (c metricsCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (c metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, metric := range c {
+		ch <- metric
+	}
+}
+
+func TestInterfaceOperStatusChanges(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_interface_oper_status_changes_total Number of operational status changes of an interface
+		# TYPE sonic_interface_oper_status_changes_total counter
+		# HELP sonic_interface_last_oper_change_timestamp_seconds Unix time of the last operational status change of an interface
+		# TYPE sonic_interface_last_oper_change_timestamp_seconds gauge
+	`
+
+	// Ethernet82 has no flap count, the other ports record neither
+	expected := `
+		sonic_interface_oper_status_changes_total{device="Ethernet80"} 7
+		sonic_interface_last_oper_change_timestamp_seconds{device="Ethernet80"} 1697102043
+		sonic_interface_last_oper_change_timestamp_seconds{device="Ethernet82"} 1704733365
+	`
+
+	if err := testutil.CollectAndCompare(interfaceCollector, strings.NewReader(metadata+expected),
+		"sonic_interface_oper_status_changes_total", "sonic_interface_last_oper_change_timestamp_seconds"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestParseOperChangeTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		ok       bool
+	}{
+		{value: "Thu Oct 12 09:14:03 2023", expected: 1697102043, ok: true},
+		{value: " Mon Jan 08 17:02:45 2024\n", expected: 1704733365, ok: true},
+		{value: "", ok: false},
+		{value: "N/A", ok: false},
+		{value: "2023-10-12 09:14:03", ok: false},
+	}
+
+	for _, tt := range tests {
+		changeTime, ok := parseOperChangeTime(tt.value)
+		if ok != tt.ok || (ok && changeTime.Unix() != tt.expected) {
+			t.Errorf("parseOperChangeTime(%q) = %v, %v, want %d, %v", tt.value, changeTime.Unix(), ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestInterfaceInfoAlias(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	redisServer.DB(4).HSet("PORT|Ethernet120", "alias", "hundredGigE31", "index", "31", "mtu", "9100", "speed", "100000")
+	redisServer.DB(4).HSet("PORT|Ethernet124", "index", "32", "mtu", "9100", "speed", "100000")
+	// the description is read from APPL_DB, not from CONFIG_DB
+	redisServer.DB(0).HSet("PORT_TABLE:Ethernet120", "admin_status", "up", "description", "rack 3 uplink")
+	redisServer.DB(4).HSet("PORT|Ethernet124", "description", "stale config")
+	defer redisServer.DB(4).Del("PORT|Ethernet120")
+	defer redisServer.DB(4).Del("PORT|Ethernet124")
+	defer redisServer.DB(0).Del("PORT_TABLE:Ethernet120")
+
+	tests := []struct {
+		interfaceName string
+		expected      string
+	}{
+		{
+			interfaceName: "Ethernet120",
+			expected:      `sonic_interface_info{alias="hundredGigE31",description="rack 3 uplink",device="Ethernet120",index="31"} 1`,
+		},
+		{
+			interfaceName: "Ethernet124",
+			expected:      `sonic_interface_info{alias="Ethernet124",description="",device="Ethernet124",index="32"} 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		info, err := redisClient.HgetAllFromDb(context.Background(), "CONFIG_DB", "PORT|"+tt.interfaceName)
+		if err != nil {
+			t.Fatalf("%s: failed to read config info: %v", tt.interfaceName, err)
+		}
+
+		portInfo, err := redisClient.HgetAllFromDb(context.Background(), "APPL_DB", "PORT_TABLE:"+tt.interfaceName)
+		if err != nil {
+			t.Fatalf("%s: failed to read port info: %v", tt.interfaceName, err)
+		}
+
+		metrics, err := interfaceCollector.collectInterfaceConfigInfo(tt.interfaceName, info, portInfo)
+		if err != nil {
+			t.Fatalf("%s: failed to collect config info: %v", tt.interfaceName, err)
+		}
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(metricsCollector(metrics))
+
+		expected := `
+			# HELP sonic_interface_info Non-numeric data about interface, value is always 1
+			# TYPE sonic_interface_info gauge
+			` + tt.expected + "\n"
+
+		if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "sonic_interface_info"); err != nil {
+			t.Errorf("%s: unexpected collecting result:\n%s", tt.interfaceName, err)
+		}
+	}
+}
+
+func TestInterfaceBreakout(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_interface_breakout_info Breakout group of an interface, value is always 1
+		# TYPE sonic_interface_breakout_info gauge
+		# HELP sonic_interface_operational_status Network device operational status:  0(DOWN), 1(UP)
+		# TYPE sonic_interface_operational_status gauge
+	`
+
+	// Ethernet80 is broken out into four children, none of which have counters yet
+	expected := `
+		sonic_interface_breakout_info{breakout_group="Ethernet80",breakout_mode="4x100G[50G,25G]",device="Ethernet80"} 1
+		sonic_interface_breakout_info{breakout_group="Ethernet80",breakout_mode="4x100G[50G,25G]",device="Ethernet82"} 1
+		sonic_interface_breakout_info{breakout_group="Ethernet80",breakout_mode="4x100G[50G,25G]",device="Ethernet84"} 1
+		sonic_interface_breakout_info{breakout_group="Ethernet80",breakout_mode="4x100G[50G,25G]",device="Ethernet86"} 1
+		sonic_interface_operational_status{device="Ethernet0"} 0
+		sonic_interface_operational_status{device="Ethernet39"} 0
+		sonic_interface_operational_status{device="Ethernet72"} 0
+		sonic_interface_operational_status{device="Ethernet76"} 0
+		sonic_interface_operational_status{device="Ethernet80"} 1
+		sonic_interface_operational_status{device="Ethernet82"} 0
+		sonic_interface_operational_status{device="Ethernet84"} 1
+		sonic_interface_operational_status{device="Ethernet86"} 0
+	`
+
+	if err := testutil.CollectAndCompare(interfaceCollector, strings.NewReader(metadata+expected),
+		"sonic_interface_breakout_info", "sonic_interface_operational_status"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestInterfaceCounterCreatedTimestamp(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	createdTimestamp := func(metric prometheus.Metric) time.Time {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		return m.GetCounter().GetCreatedTimestamp().AsTime()
+	}
+
+	first := createdTimestamp(interfaceCollector.counterMetric(interfaceCollector.interfaceReceivedBytes, 100, "Ethernet0"))
+	interfaceCollector.commitCounterSeries()
+	time.Sleep(10 * time.Millisecond)
+
+	if created := createdTimestamp(interfaceCollector.counterMetric(interfaceCollector.interfaceReceivedBytes, 200, "Ethernet0")); !created.Equal(first) {
+		t.Errorf("created timestamp changed without counter reset: %v != %v", created, first)
+	}
+	interfaceCollector.commitCounterSeries()
+
+	if created := createdTimestamp(interfaceCollector.counterMetric(interfaceCollector.interfaceReceivedBytes, 50, "Ethernet0")); !created.After(first) {
+		t.Errorf("created timestamp not updated after counter reset: %v", created)
+	}
+	interfaceCollector.commitCounterSeries()
+
+	if len(interfaceCollector.counterSeries) != 1 {
+		t.Errorf("expected 1 tracked counter series, got %d", len(interfaceCollector.counterSeries))
+	}
+}
+
+func TestInterfaceCounterSeriesCommittedOnCachedScrape(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, testConfig)
+
+	if _, err := interfaceCollector.ScrapeOnce(context.Background()); err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+
+	if len(interfaceCollector.counterSeries) != 0 {
+		t.Errorf("ScrapeOnce should not track counter series, got %d", len(interfaceCollector.counterSeries))
+	}
+
+	testutil.CollectAndCount(interfaceCollector)
+
+	if len(interfaceCollector.counterSeries) == 0 {
+		t.Errorf("expected counter series tracked after a cached scrape")
+	}
+}
+
+func TestHwCollectorRuntime(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	hwCollector := NewHwCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_hw_fan_runtime_seconds Fan accumulated runtime as reported by the platform
+		# TYPE sonic_hw_fan_runtime_seconds gauge
+		# HELP sonic_hw_psu_runtime_seconds PSU accumulated runtime as reported by the platform
+		# TYPE sonic_hw_psu_runtime_seconds gauge
+	`
+
+	expected := `
+		sonic_hw_fan_runtime_seconds{name="Fan1",slot="FanTray2"} 3.15378e+07
+		sonic_hw_psu_runtime_seconds{slot="1"} 4.32e+06
+	`
+
+	if err := testutil.CollectAndCompare(hwCollector, strings.NewReader(metadata+expected),
+		"sonic_hw_fan_runtime_seconds", "sonic_hw_psu_runtime_seconds"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestTransceiverCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	transceiverCollector := NewTransceiverCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(transceiverCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_transceiver_collector_success Whether transceiver collector succeeded
+		# TYPE sonic_transceiver_collector_success gauge
+		# HELP sonic_transceiver_max_power_watts Transceiver maximum power consumption
+		# TYPE sonic_transceiver_max_power_watts gauge
+		# HELP sonic_transceiver_power_class_info Transceiver power class, value is always 1
+		# TYPE sonic_transceiver_power_class_info gauge
+	`
+
+	expected := `
+		sonic_transceiver_collector_success 1
+		sonic_transceiver_max_power_watts{device="Ethernet0"} 1.5
+		sonic_transceiver_max_power_watts{device="Ethernet72"} 20
+		sonic_transceiver_power_class_info{device="Ethernet0",power_class="1"} 1
+		sonic_transceiver_power_class_info{device="Ethernet72",power_class="8"} 1
+	`
+
+	if err := testutil.CollectAndCompare(transceiverCollector, strings.NewReader(metadata+expected),
+		"sonic_transceiver_collector_success", "sonic_transceiver_max_power_watts", "sonic_transceiver_power_class_info"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestTransceiverFlags(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	transceiverCollector := NewTransceiverCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_transceiver_rx_los Whether a transceiver lane reports loss of signal on receive
+		# TYPE sonic_transceiver_rx_los gauge
+		# HELP sonic_transceiver_tx_fault Whether a transceiver lane reports a transmitter fault
+		# TYPE sonic_transceiver_tx_fault gauge
+		# HELP sonic_transceiver_tx_lol Whether a transceiver lane reports loss of lock on transmit
+		# TYPE sonic_transceiver_tx_lol gauge
+	`
+
+	// Ethernet76 reports all flags as N/A, Ethernet0 has no flags
+	expected := `
+		sonic_transceiver_rx_los{device="Ethernet72",lane="1"} 0
+		sonic_transceiver_rx_los{device="Ethernet72",lane="2"} 0
+		sonic_transceiver_rx_los{device="Ethernet72",lane="3"} 1
+		sonic_transceiver_rx_los{device="Ethernet72",lane="4"} 0
+		sonic_transceiver_tx_fault{device="Ethernet72",lane="1"} 0
+		sonic_transceiver_tx_fault{device="Ethernet72",lane="2"} 1
+		sonic_transceiver_tx_fault{device="Ethernet72",lane="3"} 0
+		sonic_transceiver_tx_fault{device="Ethernet72",lane="4"} 0
+		sonic_transceiver_tx_lol{device="Ethernet72",lane="1"} 0
+		sonic_transceiver_tx_lol{device="Ethernet72",lane="2"} 0
+		sonic_transceiver_tx_lol{device="Ethernet72",lane="3"} 0
+		sonic_transceiver_tx_lol{device="Ethernet72",lane="4"} 1
+	`
+
+	if err := testutil.CollectAndCompare(transceiverCollector, strings.NewReader(metadata+expected),
+		"sonic_transceiver_rx_los", "sonic_transceiver_tx_fault", "sonic_transceiver_tx_lol"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestTransceiverThresholds(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	config := testConfig
+	config.Precision = 4
+	transceiverCollector := NewTransceiverCollector(logger, redisClient, config)
+
+	metadata := `
+		# HELP sonic_transceiver_temperature_high_alarm_celsius Transceiver temperature high alarm threshold specified by the module
+		# TYPE sonic_transceiver_temperature_high_alarm_celsius gauge
+		# HELP sonic_transceiver_temperature_high_warning_celsius Transceiver temperature high warning threshold specified by the module
+		# TYPE sonic_transceiver_temperature_high_warning_celsius gauge
+		# HELP sonic_transceiver_temperature_low_alarm_celsius Transceiver temperature low alarm threshold specified by the module
+		# TYPE sonic_transceiver_temperature_low_alarm_celsius gauge
+		# HELP sonic_transceiver_temperature_low_warning_celsius Transceiver temperature low warning threshold specified by the module
+		# TYPE sonic_transceiver_temperature_low_warning_celsius gauge
+		# HELP sonic_transceiver_voltage_high_alarm_volts Transceiver supply voltage high alarm threshold specified by the module
+		# TYPE sonic_transceiver_voltage_high_alarm_volts gauge
+		# HELP sonic_transceiver_voltage_high_warning_volts Transceiver supply voltage high warning threshold specified by the module
+		# TYPE sonic_transceiver_voltage_high_warning_volts gauge
+		# HELP sonic_transceiver_voltage_low_alarm_volts Transceiver supply voltage low alarm threshold specified by the module
+		# TYPE sonic_transceiver_voltage_low_alarm_volts gauge
+		# HELP sonic_transceiver_voltage_low_warning_volts Transceiver supply voltage low warning threshold specified by the module
+		# TYPE sonic_transceiver_voltage_low_warning_volts gauge
+		# HELP sonic_transceiver_tx_power_high_alarm_dbm Transceiver transmit power high alarm threshold specified by the module
+		# TYPE sonic_transceiver_tx_power_high_alarm_dbm gauge
+		# HELP sonic_transceiver_tx_power_high_warning_dbm Transceiver transmit power high warning threshold specified by the module
+		# TYPE sonic_transceiver_tx_power_high_warning_dbm gauge
+		# HELP sonic_transceiver_tx_power_low_alarm_dbm Transceiver transmit power low alarm threshold specified by the module
+		# TYPE sonic_transceiver_tx_power_low_alarm_dbm gauge
+		# HELP sonic_transceiver_tx_power_low_warning_dbm Transceiver transmit power low warning threshold specified by the module
+		# TYPE sonic_transceiver_tx_power_low_warning_dbm gauge
+		# HELP sonic_transceiver_rx_power_high_alarm_dbm Transceiver receive power high alarm threshold specified by the module
+		# TYPE sonic_transceiver_rx_power_high_alarm_dbm gauge
+		# HELP sonic_transceiver_rx_power_high_warning_dbm Transceiver receive power high warning threshold specified by the module
+		# TYPE sonic_transceiver_rx_power_high_warning_dbm gauge
+		# HELP sonic_transceiver_rx_power_low_alarm_dbm Transceiver receive power low alarm threshold specified by the module
+		# TYPE sonic_transceiver_rx_power_low_alarm_dbm gauge
+		# HELP sonic_transceiver_rx_power_low_warning_dbm Transceiver receive power low warning threshold specified by the module
+		# TYPE sonic_transceiver_rx_power_low_warning_dbm gauge
+		# HELP sonic_transceiver_tx_bias_high_alarm_amperes Transceiver transmit bias current high alarm threshold specified by the module
+		# TYPE sonic_transceiver_tx_bias_high_alarm_amperes gauge
+		# HELP sonic_transceiver_tx_bias_high_warning_amperes Transceiver transmit bias current high warning threshold specified by the module
+		# TYPE sonic_transceiver_tx_bias_high_warning_amperes gauge
+		# HELP sonic_transceiver_tx_bias_low_alarm_amperes Transceiver transmit bias current low alarm threshold specified by the module
+		# TYPE sonic_transceiver_tx_bias_low_alarm_amperes gauge
+		# HELP sonic_transceiver_tx_bias_low_warning_amperes Transceiver transmit bias current low warning threshold specified by the module
+		# TYPE sonic_transceiver_tx_bias_low_warning_amperes gauge
+	`
+
+	// Ethernet72 reports all thresholds, Ethernet0 only some with vcchighalarm N/A
+	expected := `
+		sonic_transceiver_temperature_high_alarm_celsius{device="Ethernet0"} 95
+		sonic_transceiver_temperature_high_alarm_celsius{device="Ethernet72"} 80
+		sonic_transceiver_temperature_high_warning_celsius{device="Ethernet72"} 75
+		sonic_transceiver_temperature_low_alarm_celsius{device="Ethernet0"} -50
+		sonic_transceiver_temperature_low_alarm_celsius{device="Ethernet72"} -10
+		sonic_transceiver_temperature_low_warning_celsius{device="Ethernet72"} -5
+		sonic_transceiver_voltage_high_alarm_volts{device="Ethernet72"} 3.63
+		sonic_transceiver_voltage_high_warning_volts{device="Ethernet72"} 3.465
+		sonic_transceiver_voltage_low_alarm_volts{device="Ethernet72"} 2.97
+		sonic_transceiver_voltage_low_warning_volts{device="Ethernet72"} 3.135
+		sonic_transceiver_tx_power_high_alarm_dbm{device="Ethernet72"} 4
+		sonic_transceiver_tx_power_high_warning_dbm{device="Ethernet72"} 2
+		sonic_transceiver_tx_power_low_alarm_dbm{device="Ethernet72"} -8.2
+		sonic_transceiver_tx_power_low_warning_dbm{device="Ethernet72"} -6.2
+		sonic_transceiver_rx_power_high_alarm_dbm{device="Ethernet72"} 4.5
+		sonic_transceiver_rx_power_high_warning_dbm{device="Ethernet72"} 2.5
+		sonic_transceiver_rx_power_low_alarm_dbm{device="Ethernet72"} -10.9
+		sonic_transceiver_rx_power_low_warning_dbm{device="Ethernet72"} -8.9
+		sonic_transceiver_tx_bias_high_alarm_amperes{device="Ethernet0"} 0.012
+		sonic_transceiver_tx_bias_high_alarm_amperes{device="Ethernet72"} 0.015
+		sonic_transceiver_tx_bias_high_warning_amperes{device="Ethernet72"} 0.013
+		sonic_transceiver_tx_bias_low_alarm_amperes{device="Ethernet72"} 0.004
+		sonic_transceiver_tx_bias_low_warning_amperes{device="Ethernet72"} 0.005
+	`
+
+	if err := testutil.CollectAndCompare(transceiverCollector, strings.NewReader(metadata+expected),
+		"sonic_transceiver_temperature_high_alarm_celsius",
+		"sonic_transceiver_temperature_high_warning_celsius",
+		"sonic_transceiver_temperature_low_alarm_celsius",
+		"sonic_transceiver_temperature_low_warning_celsius",
+		"sonic_transceiver_voltage_high_alarm_volts",
+		"sonic_transceiver_voltage_high_warning_volts",
+		"sonic_transceiver_voltage_low_alarm_volts",
+		"sonic_transceiver_voltage_low_warning_volts",
+		"sonic_transceiver_tx_power_high_alarm_dbm",
+		"sonic_transceiver_tx_power_high_warning_dbm",
+		"sonic_transceiver_tx_power_low_alarm_dbm",
+		"sonic_transceiver_tx_power_low_warning_dbm",
+		"sonic_transceiver_rx_power_high_alarm_dbm",
+		"sonic_transceiver_rx_power_high_warning_dbm",
+		"sonic_transceiver_rx_power_low_alarm_dbm",
+		"sonic_transceiver_rx_power_low_warning_dbm",
+		"sonic_transceiver_tx_bias_high_alarm_amperes",
+		"sonic_transceiver_tx_bias_high_warning_amperes",
+		"sonic_transceiver_tx_bias_low_alarm_amperes",
+		"sonic_transceiver_tx_bias_low_warning_amperes"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestProcessCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	// a second orchagent adds up with the first one, python daemons are named
+	// after their script
+	redisServer.DB(6).HSet("PROCESS_STATS|1235", "UID", "0", "%CPU", "1.5", "%MEM", "0.8", "CMD", "/usr/bin/orchagent -d /var/log/swss")
+	defer redisServer.DB(6).Del("PROCESS_STATS|1235")
+	redisServer.DB(6).HSet("PROCESS_STATS|4001", "UID", "0", "%CPU", "2.0", "%MEM", "0.5", "CMD", "/usr/bin/python3 -u /usr/local/bin/xcvrd")
+	defer redisServer.DB(6).Del("PROCESS_STATS|4001")
+	redisServer.DB(6).HSet("PROCESS_STATS|4002", "UID", "0", "%CPU", "0.5", "%MEM", "0.25", "CMD", "python3 /usr/local/bin/psud")
+	defer redisServer.DB(6).Del("PROCESS_STATS|4002")
+
+	processCollector := NewProcessCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(processCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_process_collector_success Whether process collector succeeded
+		# TYPE sonic_process_collector_success gauge
+		# HELP sonic_process_cpu_percent CPU utilization of the processes of a name in percent
+		# TYPE sonic_process_cpu_percent gauge
+		# HELP sonic_process_memory_bytes Memory used by the processes of a name, derived from their share of the total memory
+		# TYPE sonic_process_memory_bytes gauge
+		# HELP sonic_process_memory_percent Memory utilization of the processes of a name in percent
+		# TYPE sonic_process_memory_percent gauge
+	`
+
+	expected := `
+		sonic_process_collector_success 1
+		sonic_process_cpu_percent{process="orchagent"} 14
+		sonic_process_cpu_percent{process="psud"} 0.5
+		sonic_process_cpu_percent{process="syncd"} 0.4
+		sonic_process_cpu_percent{process="xcvrd"} 2
+		sonic_process_memory_bytes{process="orchagent"} 6.7108864e+08
+		sonic_process_memory_bytes{process="psud"} 4.194304e+07
+		sonic_process_memory_bytes{process="syncd"} 1.84549376e+08
+		sonic_process_memory_bytes{process="xcvrd"} 8.388608e+07
+		sonic_process_memory_percent{process="orchagent"} 4
+		sonic_process_memory_percent{process="psud"} 0.25
+		sonic_process_memory_percent{process="syncd"} 1.1
+		sonic_process_memory_percent{process="xcvrd"} 0.5
+	`
+
+	if err := testutil.CollectAndCompare(processCollector, strings.NewReader(metadata+expected),
+		"sonic_process_collector_success", "sonic_process_cpu_percent", "sonic_process_memory_bytes", "sonic_process_memory_percent"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestQueueCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	queueCollector := NewQueueCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(queueCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_queue_bytes_total Number of bytes transmitted through a queue
+		# TYPE sonic_queue_bytes_total counter
+		# HELP sonic_queue_dropped_packets_total Number of packets dropped by a queue
+		# TYPE sonic_queue_dropped_packets_total counter
+		# HELP sonic_queue_packets_total Number of packets transmitted through a queue
+		# TYPE sonic_queue_packets_total counter
+	`
+
+	expected := `
+		sonic_queue_bytes_total{device="Ethernet0",queue="0",type="unicast"} 64000
+		sonic_queue_bytes_total{device="Ethernet0",queue="8",type="multicast"} 768
+		sonic_queue_dropped_packets_total{device="Ethernet0",queue="0",type="unicast"} 0
+		sonic_queue_dropped_packets_total{device="Ethernet0",queue="3",type="unicast"} 7
+		sonic_queue_packets_total{device="Ethernet0",queue="0",type="unicast"} 1000
+		sonic_queue_packets_total{device="Ethernet0",queue="3",type="unicast"} 250
+		sonic_queue_packets_total{device="Ethernet0",queue="8",type="multicast"} 12
+	`
+
+	if err := testutil.CollectAndCompare(queueCollector, strings.NewReader(metadata+expected),
+		"sonic_queue_bytes_total", "sonic_queue_dropped_packets_total", "sonic_queue_packets_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	metadata = `
+		# HELP sonic_queue_wred_dropped_packets_total Number of packets dropped by WRED on a queue per packet color
+		# TYPE sonic_queue_wred_dropped_packets_total counter
+		# HELP sonic_queue_wred_ecn_marked_packets_total Number of packets ECN marked by WRED on a queue
+		# TYPE sonic_queue_wred_ecn_marked_packets_total counter
+	`
+
+	// Queue 8 has no WRED counters, queue 0 no WRED drop counters
+	expected = `
+		sonic_queue_wred_dropped_packets_total{color="green",device="Ethernet0",queue="3"} 3
+		sonic_queue_wred_dropped_packets_total{color="red",device="Ethernet0",queue="3"} 1
+		sonic_queue_wred_dropped_packets_total{color="yellow",device="Ethernet0",queue="3"} 2
+		sonic_queue_wred_ecn_marked_packets_total{device="Ethernet0",queue="0"} 0
+		sonic_queue_wred_ecn_marked_packets_total{device="Ethernet0",queue="3"} 42
+	`
+
+	if err := testutil.CollectAndCompare(queueCollector, strings.NewReader(metadata+expected),
+		"sonic_queue_wred_dropped_packets_total", "sonic_queue_wred_ecn_marked_packets_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	metadata = `
+		# HELP sonic_queue_occupancy_bytes Number of bytes currently buffered in a queue
+		# TYPE sonic_queue_occupancy_bytes gauge
+	`
+
+	// Queue 8 has no occupancy field
+	expected = `
+		sonic_queue_occupancy_bytes{device="Ethernet0",queue="0"} 4096
+		sonic_queue_occupancy_bytes{device="Ethernet0",queue="3"} 0
+	`
+
+	if err := testutil.CollectAndCompare(queueCollector, strings.NewReader(metadata+expected),
+		"sonic_queue_occupancy_bytes"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestPfcWdCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	pfcWdCollector := NewPfcWdCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(pfcWdCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_pfcwd_collector_success Whether pfc watchdog collector succeeded
+		# TYPE sonic_pfcwd_collector_success gauge
+		# HELP sonic_pfcwd_restored_total Number of queues restored after a PFC storm
+		# TYPE sonic_pfcwd_restored_total counter
+		# HELP sonic_pfcwd_status PFC watchdog queue status: 0(OK), 1(STORMED)
+		# TYPE sonic_pfcwd_status gauge
+		# HELP sonic_pfcwd_storm_detected_total Number of PFC storms detected on a queue
+		# TYPE sonic_pfcwd_storm_detected_total counter
+	`
+
+	// Queue 3 is stormed, queue 4 recovered. Ethernet39 has no PFC watchdog configured.
+	expected := `
+		sonic_pfcwd_collector_success 1
+		sonic_pfcwd_restored_total{device="Ethernet0",queue="3"} 1
+		sonic_pfcwd_restored_total{device="Ethernet0",queue="4"} 1
+		sonic_pfcwd_status{device="Ethernet0",queue="3"} 1
+		sonic_pfcwd_status{device="Ethernet0",queue="4"} 0
+		sonic_pfcwd_storm_detected_total{device="Ethernet0",queue="3"} 2
+		sonic_pfcwd_storm_detected_total{device="Ethernet0",queue="4"} 1
+	`
+
+	if err := testutil.CollectAndCompare(pfcWdCollector, strings.NewReader(metadata+expected),
+		"sonic_pfcwd_collector_success", "sonic_pfcwd_restored_total", "sonic_pfcwd_status", "sonic_pfcwd_storm_detected_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestBufferCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	bufferCollector := NewBufferCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(bufferCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_buffer_collector_success Whether buffer collector succeeded
+		# TYPE sonic_buffer_collector_success gauge
+		# HELP sonic_buffer_pool_watermark_bytes Peak shared buffer pool occupancy since the persistent watermark was last cleared
+		# TYPE sonic_buffer_pool_watermark_bytes gauge
+		# HELP sonic_buffer_priority_group_watermark_bytes Peak shared buffer occupancy of an ingress priority group since the persistent watermark was last cleared
+		# TYPE sonic_buffer_priority_group_watermark_bytes gauge
+	`
+
+	// egress_lossy_pool has an unparsable watermark and Ethernet39:0 has no watermarks
+	expected := `
+		sonic_buffer_collector_success 1
+		sonic_buffer_pool_watermark_bytes{pool="ingress_lossless_pool"} 3.072e+06
+		sonic_buffer_priority_group_watermark_bytes{device="Ethernet0",pg="3"} 20480
+		sonic_buffer_priority_group_watermark_bytes{device="Ethernet0",pg="4"} 0
+	`
+
+	if err := testutil.CollectAndCompare(bufferCollector, strings.NewReader(metadata+expected),
+		"sonic_buffer_collector_success", "sonic_buffer_pool_watermark_bytes", "sonic_buffer_priority_group_watermark_bytes"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestBufferWatermarkClear(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	subscriber := redisServer.NewSubscriber()
+	defer subscriber.Close()
+	subscriber.Subscribe("WATERMARK_CLEAR_REQUEST")
+
+	received := make(chan string, 10)
+	go func() {
+		for message := range subscriber.Messages() {
+			received <- message.Message
+		}
+	}()
+
+	watermarkMetrics := []string{"sonic_buffer_pool_watermark_bytes", "sonic_buffer_priority_group_watermark_bytes"}
+
+	// without the option watermarks are read but not cleared
+	if count := testutil.CollectAndCount(NewBufferCollector(logger, redisClient, Config{}), watermarkMetrics...); count != 3 {
+		t.Errorf("expected 3 watermark series, got %d", count)
+	}
+
+	if count := testutil.CollectAndCount(NewBufferCollector(logger, redisClient, Config{WatermarkClear: true}), watermarkMetrics...); count != 3 {
+		t.Errorf("expected 3 watermark series, got %d", count)
+	}
+
+	// a clear request of the first collect would be received first
+	for _, expected := range []string{`["PERSISTENT","BUFFER_POOL"]`, `["PERSISTENT","PG_SHARED"]`} {
+		select {
+		case message := <-received:
+			if message != expected {
+				t.Errorf("unexpected clear request %s, want %s", message, expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("clear request %s was not published", expected)
+		}
+	}
+}
+
+func TestSystemCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	systemCollector := NewSystemCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(systemCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_system_collector_success Whether system collector succeeded
+		# TYPE sonic_system_collector_success gauge
+		# HELP sonic_system_cpu_utilization_ratio Share of CPU time spent in user and system mode
+		# TYPE sonic_system_cpu_utilization_ratio gauge
+		# HELP sonic_system_memory_total_bytes Total memory of the system
+		# TYPE sonic_system_memory_total_bytes gauge
+		# HELP sonic_system_memory_used_bytes Memory used by the system
+		# TYPE sonic_system_memory_used_bytes gauge
+	`
+
+	expected := `
+		sonic_system_collector_success 1
+		sonic_system_cpu_utilization_ratio 0.2
+		sonic_system_memory_total_bytes 1.6777216e+10
+		sonic_system_memory_used_bytes 4.194304e+09
+	`
+
+	if err := testutil.CollectAndCompare(systemCollector, strings.NewReader(metadata+expected),
+		"sonic_system_collector_success", "sonic_system_cpu_utilization_ratio", "sonic_system_memory_total_bytes", "sonic_system_memory_used_bytes"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestSystemReady(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	systemCollector := NewSystemCollector(logger, redisClient, Config{UptimeFile: "../../fixtures/test/uptime"})
+
+	metadata := `
+		# HELP sonic_services_not_ready Service reported not ready by system monitor, value is always 1
+		# TYPE sonic_services_not_ready gauge
+		# HELP sonic_system_ready Whether system monitor reports the system ready: 0(DOWN), 1(UP)
+		# TYPE sonic_system_ready gauge
+		# HELP sonic_system_uptime_seconds Number of seconds since the system booted
+		# TYPE sonic_system_uptime_seconds gauge
+	`
+
+	expected := `
+		sonic_system_ready 1
+		sonic_system_uptime_seconds 123456.78
+	`
+
+	if err := testutil.CollectAndCompare(systemCollector, strings.NewReader(metadata+expected),
+		"sonic_services_not_ready", "sonic_system_ready", "sonic_system_uptime_seconds"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	redisServer.DB(6).HSet("SYSTEM_READY|SYSTEM_STATE", "Status", "DOWN")
+	redisServer.DB(6).HSet("ALL_SERVICE_STATUS|swss", "service_status", "Down", "app_ready_status", "Down")
+	redisServer.DB(6).HSet("ALL_SERVICE_STATUS|teamd", "service_status", "OK", "app_ready_status", "Down")
+	defer redisServer.DB(6).HSet("SYSTEM_READY|SYSTEM_STATE", "Status", "UP")
+	defer redisServer.DB(6).HSet("ALL_SERVICE_STATUS|swss", "service_status", "OK", "app_ready_status", "OK")
+	defer redisServer.DB(6).Del("ALL_SERVICE_STATUS|teamd")
+
+	expected = `
+		sonic_services_not_ready{service="swss"} 1
+		sonic_services_not_ready{service="teamd"} 1
+		sonic_system_ready 0
+		sonic_system_uptime_seconds 123456.78
+	`
+
+	if err := testutil.CollectAndCompare(systemCollector, strings.NewReader(metadata+expected),
+		"sonic_services_not_ready", "sonic_system_ready", "sonic_system_uptime_seconds"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestRebootCauseCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	rebootCauseCollector := NewRebootCauseCollector(logger, redisClient, Config{CacheDuration: 0})
+
+	problems, err := testutil.CollectAndLint(rebootCauseCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_reboot_cause_info Cause of the most recent reboot, value is always 1
+		# TYPE sonic_reboot_cause_info gauge
+		# HELP sonic_reboot_unexpected Whether the most recent reboot was not a planned reboot: 0(PLANNED), 1(UNEXPECTED)
+		# TYPE sonic_reboot_unexpected gauge
+	`
+
+	// The kernel panic is the latest of the three reboots in the history
+	expected := `
+		sonic_reboot_cause_info{cause="Kernel Panic",time="Sat Oct 10 03:15:42 UTC 2026",user="N/A"} 1
+		sonic_reboot_unexpected 1
+	`
+
+	if err := testutil.CollectAndCompare(rebootCauseCollector, strings.NewReader(metadata+expected),
+		"sonic_reboot_cause_info", "sonic_reboot_unexpected"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	redisServer.DB(6).HSet("REBOOT_CAUSE|2026_10_16_22_05_11", "cause", "warm-reboot", "time", "Fri Oct 16 22:05:11 UTC 2026", "user", "admin")
+	defer redisServer.DB(6).Del("REBOOT_CAUSE|2026_10_16_22_05_11")
+
+	expected = `
+		sonic_reboot_cause_info{cause="warm-reboot",time="Fri Oct 16 22:05:11 UTC 2026",user="admin"} 1
+		sonic_reboot_unexpected 0
+	`
+
+	if err := testutil.CollectAndCompare(rebootCauseCollector, strings.NewReader(metadata+expected),
+		"sonic_reboot_cause_info", "sonic_reboot_unexpected"); err != nil {
+		t.Errorf("unexpected collecting result after planned reboot:\n%s", err)
+	}
+}
+
+func TestVersionCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	metadata := `
+		# HELP sonic_version_info SONiC image version and platform, value is always 1
+		# TYPE sonic_version_info gauge
+	`
+
+	tests := []struct {
+		name        string
+		versionFile string
+		expected    string
+	}{
+		{
+			name:        "version file",
+			versionFile: "../../fixtures/test/sonic_version.yml",
+			expected: `
+				sonic_version_info{asic_type="broadcom",hwsku="Accton-AS7726-32X",kernel="6.1.0-22-2-amd64",platform="x86_64-accton_as7726_32x-r0",version="SONiC.202405.0-a1b2c3d"} 1
+			`,
+		},
+		{
+			name:        "missing version file",
+			versionFile: "../../fixtures/test/missing_sonic_version.yml",
+			expected: `
+				sonic_version_info{asic_type="",hwsku="Accton-AS7726-32X",kernel="",platform="x86_64-accton_as7726_32x-r0",version=""} 1
+			`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versionCollector := NewVersionCollector(logger, redisClient, Config{VersionFile: tt.versionFile})
+
+			problems, err := testutil.CollectAndLint(versionCollector)
+			if err != nil {
+				t.Error("metric lint completed with errors")
+			}
+
+			for _, problem := range problems {
+				t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+			}
+
+			if err := testutil.CollectAndCompare(versionCollector, strings.NewReader(metadata+tt.expected), "sonic_version_info"); err != nil {
+				t.Errorf("unexpected collecting result:\n%s", err)
+			}
+		})
+	}
+}
+
+func TestFdbCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	fdbCollector := NewFdbCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(fdbCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_fdb_entries Number of FDB (MAC address table) entries of a VLAN
+		# TYPE sonic_fdb_entries gauge
+		# HELP sonic_fdb_total_entries Number of entries in the FDB (MAC address table)
+		# TYPE sonic_fdb_total_entries gauge
+	`
+
+	expected := `
+		sonic_fdb_entries{vlan="100"} 3
+		sonic_fdb_entries{vlan="200"} 1
+		sonic_fdb_entries{vlan="300"} 1
+		sonic_fdb_total_entries 5
+	`
+
+	if err := testutil.CollectAndCompare(fdbCollector, strings.NewReader(metadata+expected),
+		"sonic_fdb_entries", "sonic_fdb_total_entries"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestNeighborCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	neighborCollector := NewNeighborCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(neighborCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_neighbor_entries Number of neighbor (ARP/NDP) table entries of an address family
+		# TYPE sonic_neighbor_entries gauge
+		# HELP sonic_neighbor_total_entries Number of entries in the neighbor (ARP/NDP) table
+		# TYPE sonic_neighbor_total_entries gauge
+	`
+
+	expected := `
+		sonic_neighbor_entries{family="ipv4"} 3
+		sonic_neighbor_entries{family="ipv6"} 2
+		sonic_neighbor_total_entries 5
+	`
+
+	if err := testutil.CollectAndCompare(neighborCollector, strings.NewReader(metadata+expected),
+		"sonic_neighbor_entries", "sonic_neighbor_total_entries"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestRouteCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	routeCollector := NewRouteCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(routeCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_route_entries Number of installed routes of a VRF and address family
+		# TYPE sonic_route_entries gauge
+	`
+
+	// host routes are keyed without a prefix length
+	expected := `
+		sonic_route_entries{family="ipv4",vrf="Vrf-red"} 2
+		sonic_route_entries{family="ipv4",vrf="default"} 3
+		sonic_route_entries{family="ipv6",vrf="Vrf-red"} 3
+		sonic_route_entries{family="ipv6",vrf="default"} 2
+	`
+
+	if err := testutil.CollectAndCompare(routeCollector, strings.NewReader(metadata+expected), "sonic_route_entries"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestPortChannelCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	portChannelCollector := NewPortChannelCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(portChannelCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_portchannel_member_status PortChannel member status: 0(UNSELECTED), 1(SELECTED)
+		# TYPE sonic_portchannel_member_status gauge
+		# HELP sonic_portchannel_members Number of members configured in a PortChannel
+		# TYPE sonic_portchannel_members gauge
+		# HELP sonic_portchannel_oper_status PortChannel operational status: 0(DOWN), 1(UP)
+		# TYPE sonic_portchannel_oper_status gauge
+	`
+
+	// PortChannel02 has no members, Ethernet76 is enabled but not selected by LACP
+	expected := `
+		sonic_portchannel_member_status{device="PortChannel01",member="Ethernet72"} 1
+		sonic_portchannel_member_status{device="PortChannel01",member="Ethernet76"} 0
+		sonic_portchannel_members{device="PortChannel01"} 2
+		sonic_portchannel_members{device="PortChannel02"} 0
+		sonic_portchannel_oper_status{device="PortChannel01"} 1
+		sonic_portchannel_oper_status{device="PortChannel02"} 0
+	`
+
+	if err := testutil.CollectAndCompare(portChannelCollector, strings.NewReader(metadata+expected),
+		"sonic_portchannel_member_status", "sonic_portchannel_members", "sonic_portchannel_oper_status"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestMclagCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	metadata := `
+		# HELP sonic_mclag_collector_success Whether mclag collector succeeded
+		# TYPE sonic_mclag_collector_success gauge
+		# HELP sonic_mclag_keepalive_status MCLAG keepalive status with the peer: 0(ERROR), 1(OK)
+		# TYPE sonic_mclag_keepalive_status gauge
+		# HELP sonic_mclag_peer_link_status MCLAG peer link operational status: 0(DOWN), 1(UP)
+		# TYPE sonic_mclag_peer_link_status gauge
+		# HELP sonic_mclag_session_status MCLAG ICCP session status with the peer: 0(DOWN), 1(UP)
+		# TYPE sonic_mclag_session_status gauge
+	`
+	names := []string{"sonic_mclag_collector_success", "sonic_mclag_keepalive_status", "sonic_mclag_peer_link_status", "sonic_mclag_session_status"}
+
+	// Devices not configured for MCLAG only report success
+	expected := `
+		sonic_mclag_collector_success 1
+	`
+
+	if err := testutil.CollectAndCompare(NewMclagCollector(logger, redisClient, testConfig), strings.NewReader(metadata+expected), names...); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	redisServer.DB(4).HSet("MCLAG_DOMAIN|1", "source_ip", "10.0.0.1", "peer_ip", "10.0.0.2", "peer_link", "PortChannel01")
+	redisServer.DB(4).HSet("MCLAG_DOMAIN|2", "source_ip", "10.0.1.1", "peer_ip", "10.0.1.2", "peer_link", "PortChannel02")
+	redisServer.DB(4).HSet("MCLAG_DOMAIN|3", "source_ip", "10.0.2.1", "peer_ip", "10.0.2.2")
+	redisServer.DB(6).HSet("MCLAG_TABLE|1", "oper_status", "up", "keepalive", "OK", "role", "active")
+	redisServer.DB(6).HSet("MCLAG_TABLE|2", "oper_status", "down", "keepalive", "ERROR", "role", "standby")
+	defer redisServer.DB(4).Del("MCLAG_DOMAIN|1")
+	defer redisServer.DB(4).Del("MCLAG_DOMAIN|2")
+	defer redisServer.DB(4).Del("MCLAG_DOMAIN|3")
+	defer redisServer.DB(6).Del("MCLAG_TABLE|1")
+	defer redisServer.DB(6).Del("MCLAG_TABLE|2")
+
+	mclagCollector := NewMclagCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(mclagCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	// Domain 2 lost its peer over a down peer link, domain 3 has no state and no peer link
+	expected = `
+		sonic_mclag_collector_success 1
+		sonic_mclag_keepalive_status{domain="1"} 1
+		sonic_mclag_keepalive_status{domain="2"} 0
+		sonic_mclag_keepalive_status{domain="3"} 0
+		sonic_mclag_peer_link_status{domain="1"} 1
+		sonic_mclag_peer_link_status{domain="2"} 0
+		sonic_mclag_session_status{domain="1"} 1
+		sonic_mclag_session_status{domain="2"} 0
+		sonic_mclag_session_status{domain="3"} 0
+	`
+
+	if err := testutil.CollectAndCompare(mclagCollector, strings.NewReader(metadata+expected), names...); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestVlanCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	vlanCollector := NewVlanCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(vlanCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_vlan_info Configured VLAN, value is always 1
+		# TYPE sonic_vlan_info gauge
+		# HELP sonic_vlan_member VLAN membership of a port, value is always 1
+		# TYPE sonic_vlan_member gauge
+	`
+
+	// Vlan200 has no members
+	expected := `
+		sonic_vlan_info{vlan="Vlan100"} 1
+		sonic_vlan_info{vlan="Vlan200"} 1
+		sonic_vlan_member{device="Ethernet0",tagging_mode="untagged",vlan="Vlan100"} 1
+		sonic_vlan_member{device="PortChannel01",tagging_mode="tagged",vlan="Vlan100"} 1
+	`
+
+	if err := testutil.CollectAndCompare(vlanCollector, strings.NewReader(metadata+expected),
+		"sonic_vlan_info", "sonic_vlan_member"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestRedisCommandMetrics(t *testing.T) {
+	commandMetrics := NewRedisCommandMetrics(testConfig)
+
+	commandMetrics.Observe("STATE_DB", "hgetall", 2*time.Millisecond, nil)
+	commandMetrics.Observe("STATE_DB", "hgetall", 3*time.Millisecond, errors.New("connection refused"))
+	commandMetrics.Observe("COUNTERS_DB", "scan", time.Millisecond, nil)
+
+	problems, err := testutil.CollectAndLint(commandMetrics)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	expected := `
+		# HELP sonic_redis_command_errors_total Number of redis commands issued by the exporter that failed
+		# TYPE sonic_redis_command_errors_total counter
+		sonic_redis_command_errors_total{command="hgetall",db="STATE_DB"} 1
+	`
+
+	if err := testutil.CollectAndCompare(commandMetrics, strings.NewReader(expected), "sonic_redis_command_errors_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	if count := testutil.CollectAndCount(commandMetrics, "sonic_redis_command_duration_seconds"); count != 2 {
+		t.Errorf("unexpected number of duration series: %d", count)
+	}
+}
+
+func TestRedisReconnects(t *testing.T) {
+	restartedServer := miniredis.RunT(t)
+
+	t.Setenv("REDIS_ADDRESS", restartedServer.Addr())
+	restartedClient, err := redis.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restartedClient.Close()
+
+	commandMetrics := NewRedisCommandMetrics(testConfig)
+	restartedClient.SetReconnectObserver(commandMetrics.ObserveReconnect)
+
+	ctx := context.Background()
+	if err := restartedClient.Ping(ctx, "STATE_DB"); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+
+	// A restart of redis drops the connection of STATE_DB
+	restartedServer.Close()
+	if err := restartedServer.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if err := restartedClient.Ping(ctx, "STATE_DB"); err != nil {
+		t.Fatalf("ping after restart failed: %v", err)
+	}
+
+	expected := `
+		# HELP sonic_redis_reconnects_total Number of connections to a redis database re-established after a connection broke
+		# TYPE sonic_redis_reconnects_total counter
+		sonic_redis_reconnects_total{db="STATE_DB"} 1
+	`
+
+	if err := testutil.CollectAndCompare(commandMetrics, strings.NewReader(expected), "sonic_redis_reconnects_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestCoppCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	coppCollector := NewCoppCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(coppCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_copp_green_packets_total Number of packets of a COPP trap group conforming to its policer
+		# TYPE sonic_copp_green_packets_total counter
+		# HELP sonic_copp_red_bytes_total Number of bytes of a COPP trap group dropped by its policer
+		# TYPE sonic_copp_red_bytes_total counter
+		# HELP sonic_copp_red_packets_total Number of packets of a COPP trap group dropped by its policer
+		# TYPE sonic_copp_red_packets_total counter
+	`
+
+	expected := `
+		sonic_copp_green_packets_total{trap_group="queue1_group2"} 88210
+		sonic_copp_green_packets_total{trap_group="queue4_group1"} 120034
+		sonic_copp_red_bytes_total{trap_group="queue1_group2"} 196096
+		sonic_copp_red_bytes_total{trap_group="queue4_group1"} 0
+		sonic_copp_red_packets_total{trap_group="queue1_group2"} 1532
+		sonic_copp_red_packets_total{trap_group="queue4_group1"} 0
+	`
+
+	if err := testutil.CollectAndCompare(coppCollector, strings.NewReader(metadata+expected),
+		"sonic_copp_green_packets_total", "sonic_copp_red_bytes_total", "sonic_copp_red_packets_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestAclRuleCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	aclRuleCollector := NewAclRuleCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(aclRuleCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_acl_rule_bytes_total Number of bytes matched by an ACL rule
+		# TYPE sonic_acl_rule_bytes_total counter
+		# HELP sonic_acl_rule_packets_total Number of packets matched by an ACL rule
+		# TYPE sonic_acl_rule_packets_total counter
+	`
+
+	// SSH_ONLY|RULE_1 has no counter
+	expected := `
+		sonic_acl_rule_bytes_total{rule="RULE_1",table="DATAACL"} 194944
+		sonic_acl_rule_bytes_total{rule="RULE_2",table="DATAACL"} 0
+		sonic_acl_rule_bytes_total{rule="RULE_1",table="EVERFLOW"} 5376
+		sonic_acl_rule_packets_total{rule="RULE_1",table="DATAACL"} 1523
+		sonic_acl_rule_packets_total{rule="RULE_2",table="DATAACL"} 0
+		sonic_acl_rule_packets_total{rule="RULE_1",table="EVERFLOW"} 42
+	`
+
+	if err := testutil.CollectAndCompare(aclRuleCollector, strings.NewReader(metadata+expected),
+		"sonic_acl_rule_bytes_total", "sonic_acl_rule_packets_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestWarmbootCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	warmbootCollector := NewWarmbootCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(warmbootCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_warmboot_enabled Whether warm restart is enabled for a module: 0(DISABLED), 1(ENABLED)
+		# TYPE sonic_warmboot_enabled gauge
+		# HELP sonic_warmboot_state Warm restart state of a module: -1(UNKNOWN), 0(DISABLED), 1(INITIALIZED), 2(RESTORED), 3(REPLAYED), 4(RECONCILED)
+		# TYPE sonic_warmboot_state gauge
+	`
+
+	// teamd is enabled but has not restarted, vlanmgrd has no state yet
+	expected := `
+		sonic_warmboot_enabled{module="bgp"} 1
+		sonic_warmboot_enabled{module="system"} 0
+		sonic_warmboot_enabled{module="teamd"} 1
+		sonic_warmboot_state{module="bgp"} 4
+		sonic_warmboot_state{module="neighsyncd"} 1
+		sonic_warmboot_state{module="orchagent"} 2
+		sonic_warmboot_state{module="syncd"} -1
+	`
+
+	if err := testutil.CollectAndCompare(warmbootCollector, strings.NewReader(metadata+expected),
+		"sonic_warmboot_enabled", "sonic_warmboot_state"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestNtpCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	ntpCollector := NewNtpCollector(logger, redisClient, Config{CacheDuration: 0})
+
+	problems, err := testutil.CollectAndLint(ntpCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_ntp_offset_seconds Offset of the system clock to the selected NTP server
+		# TYPE sonic_ntp_offset_seconds gauge
+		# HELP sonic_ntp_synced Whether the system clock is synchronized by NTP: 0(UNSYNCHRONIZED), 1(SYNCHRONIZED)
+		# TYPE sonic_ntp_synced gauge
+	`
+
+	expected := `
+		sonic_ntp_offset_seconds -0.00125
+		sonic_ntp_synced 1
+	`
+
+	if err := testutil.CollectAndCompare(ntpCollector, strings.NewReader(metadata+expected),
+		"sonic_ntp_offset_seconds", "sonic_ntp_synced"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	// Without a selected server there is no offset
+	synced := redisServer.DB(6).HGet("NTP_STATUS|localhost", "status")
+	offset := redisServer.DB(6).HGet("NTP_STATUS|localhost", "offset")
+	redisServer.DB(6).HSet("NTP_STATUS|localhost", "status", "unsynchronized")
+	redisServer.DB(6).HDel("NTP_STATUS|localhost", "offset")
+	defer redisServer.DB(6).HSet("NTP_STATUS|localhost", "status", synced, "offset", offset)
+
+	expected = `
+		sonic_ntp_synced 0
+	`
+
+	if err := testutil.CollectAndCompare(ntpCollector, strings.NewReader(metadata+expected),
+		"sonic_ntp_offset_seconds", "sonic_ntp_synced"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestStormControlCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	stormControlCollector := NewStormControlCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(stormControlCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_storm_control_rate_bytes Storm control rate limit of a traffic type on an interface in bytes per second
+		# TYPE sonic_storm_control_rate_bytes gauge
+	`
+
+	// Ethernet72 only limits broadcast, storm control of Ethernet76 is disabled
+	expected := `
+		sonic_storm_control_rate_bytes{device="Ethernet0",type="broadcast"} 1.25e+06
+		sonic_storm_control_rate_bytes{device="Ethernet0",type="unknown-multicast"} 2.5e+06
+		sonic_storm_control_rate_bytes{device="Ethernet0",type="unknown-unicast"} 3.75e+06
+		sonic_storm_control_rate_bytes{device="Ethernet72",type="broadcast"} 1e+06
+	`
+
+	if err := testutil.CollectAndCompare(stormControlCollector, strings.NewReader(metadata+expected),
+		"sonic_storm_control_rate_bytes"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestVxlanCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	vxlanCollector := NewVxlanCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(vxlanCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_vxlan_tunnel_info VXLAN tunnel to a remote VTEP, value is always 1
+		# TYPE sonic_vxlan_tunnel_info gauge
+		# HELP sonic_vxlan_tunnel_rx_bytes_total Number of bytes received through a VXLAN tunnel
+		# TYPE sonic_vxlan_tunnel_rx_bytes_total counter
+		# HELP sonic_vxlan_tunnel_tx_bytes_total Number of bytes transmitted through a VXLAN tunnel
+		# TYPE sonic_vxlan_tunnel_tx_bytes_total counter
+	`
+
+	// The tunnel to 10.1.0.4 has no counters
+	expected := `
+		sonic_vxlan_tunnel_info{dst_ip="10.1.0.2",src_ip="10.1.0.1"} 1
+		sonic_vxlan_tunnel_info{dst_ip="10.1.0.3",src_ip="10.1.0.1"} 1
+		sonic_vxlan_tunnel_info{dst_ip="10.1.0.4",src_ip="10.1.0.1"} 1
+		sonic_vxlan_tunnel_rx_bytes_total{dst_ip="10.1.0.2"} 1.048576e+06
+		sonic_vxlan_tunnel_rx_bytes_total{dst_ip="10.1.0.3"} 0
+		sonic_vxlan_tunnel_tx_bytes_total{dst_ip="10.1.0.2"} 2.097152e+06
+		sonic_vxlan_tunnel_tx_bytes_total{dst_ip="10.1.0.3"} 512
+	`
+
+	if err := testutil.CollectAndCompare(vxlanCollector, strings.NewReader(metadata+expected),
+		"sonic_vxlan_tunnel_info", "sonic_vxlan_tunnel_rx_bytes_total", "sonic_vxlan_tunnel_tx_bytes_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestSflowCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	sflowCollector := NewSflowCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(sflowCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_sflow_admin_state Whether sFlow is enabled globally: 0(DOWN), 1(UP)
+		# TYPE sonic_sflow_admin_state gauge
+		# HELP sonic_sflow_enabled Whether sFlow sampling is active on an interface, taking the global admin state into account
+		# TYPE sonic_sflow_enabled gauge
+		# HELP sonic_sflow_sample_rate sFlow sampling rate of an interface, one in sample_rate packets is sampled
+		# TYPE sonic_sflow_sample_rate gauge
+	`
+
+	// Ethernet4 is disabled per interface, Ethernet8 inherits the admin state of the all session
+	expected := `
+		sonic_sflow_admin_state 1
+		sonic_sflow_enabled{device="Ethernet0"} 1
+		sonic_sflow_enabled{device="Ethernet4"} 0
+		sonic_sflow_enabled{device="Ethernet8"} 1
+		sonic_sflow_sample_rate{device="Ethernet0"} 40000
+		sonic_sflow_sample_rate{device="Ethernet4"} 40000
+		sonic_sflow_sample_rate{device="Ethernet8"} 10000
+	`
+
+	if err := testutil.CollectAndCompare(sflowCollector, strings.NewReader(metadata+expected),
+		"sonic_sflow_admin_state", "sonic_sflow_enabled", "sonic_sflow_sample_rate"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestSflowCollectorGloballyDisabled(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	redisServer.DB(4).HSet("SFLOW|global", "admin_state", "down")
+	redisServer.DB(4).HSet("SFLOW_SESSION|all", "admin_state", "down")
+	defer redisServer.DB(4).HSet("SFLOW|global", "admin_state", "up")
+	defer redisServer.DB(4).HSet("SFLOW_SESSION|all", "admin_state", "up")
+
+	sflowCollector := NewSflowCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_sflow_admin_state Whether sFlow is enabled globally: 0(DOWN), 1(UP)
+		# TYPE sonic_sflow_admin_state gauge
+		# HELP sonic_sflow_enabled Whether sFlow sampling is active on an interface, taking the global admin state into account
+		# TYPE sonic_sflow_enabled gauge
+	`
+
+	// Sessions enabled per interface are not sampling while sFlow is disabled globally
+	expected := `
+		sonic_sflow_admin_state 0
+		sonic_sflow_enabled{device="Ethernet0"} 0
+		sonic_sflow_enabled{device="Ethernet4"} 0
+		sonic_sflow_enabled{device="Ethernet8"} 0
+	`
+
+	if err := testutil.CollectAndCompare(sflowCollector, strings.NewReader(metadata+expected),
+		"sonic_sflow_admin_state", "sonic_sflow_enabled"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestDhcpRelayCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	dhcpRelayCollector := NewDhcpRelayCollector(logger, redisClient, testConfig)
+
+	// an unparsable counter is skipped without failing the others
+	redisServer.DB(6).HSet("DHCPv4_COUNTER_TABLE|Vlan200", "RX", "{'Discover':'N/A','Request':'3'}")
+	defer redisServer.DB(6).Del("DHCPv4_COUNTER_TABLE|Vlan200")
+
+	problems, err := testutil.CollectAndLint(dhcpRelayCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_dhcp_relay_packets_total Number of DHCPv4 packets relayed on an interface per direction and message type
+		# TYPE sonic_dhcp_relay_packets_total counter
+	`
+
+	expected := `
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="rx",type="Ack"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="rx",type="Decline"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="rx",type="Discover"} 25
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="rx",type="Inform"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="rx",type="Nak"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="rx",type="Offer"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="rx",type="Release"} 1
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="rx",type="Request"} 24
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="rx",type="Unknown"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="tx",type="Ack"} 23
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="tx",type="Decline"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="tx",type="Discover"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="tx",type="Inform"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="tx",type="Nak"} 1
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="tx",type="Offer"} 25
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="tx",type="Release"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="tx",type="Request"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan100",direction="tx",type="Unknown"} 0
+		sonic_dhcp_relay_packets_total{device="Vlan200",direction="rx",type="Request"} 3
+	`
+
+	if err := testutil.CollectAndCompare(dhcpRelayCollector, strings.NewReader(metadata+expected),
+		"sonic_dhcp_relay_packets_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestParseDhcpCounters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]string
+	}{
+		{input: "{'Discover':'12','Offer':'3'}", expected: map[string]string{"Discover": "12", "Offer": "3"}},
+		{input: `{"Request": "5", "Ack": "4"}`, expected: map[string]string{"Request": "5", "Ack": "4"}},
+		{input: "{}", expected: map[string]string{}},
+		{input: "{'Discover','Offer':'3'}", expected: map[string]string{"Offer": "3"}},
+	}
+
+	for _, test := range tests {
+		counters := parseDhcpCounters(test.input)
+		if !reflect.DeepEqual(counters, test.expected) {
+			t.Errorf("parseDhcpCounters(%q) = %v, expected %v", test.input, counters, test.expected)
+		}
+	}
+}
+
+func TestSensorCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	// some platforms append the unit to readings
+	redisServer.DB(6).HSet("CURRENT_INFO|ISENSOR1", "current", "2500 mA", "unit", "mA", "high_threshold", "N/A", "critical_high_threshold", "4000 mA")
+	defer redisServer.DB(6).Del("CURRENT_INFO|ISENSOR1")
+
+	sensorCollector := NewSensorCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(sensorCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_sensor_current_amperes Current reported by a board current sensor
+		# TYPE sonic_sensor_current_amperes gauge
+		# HELP sonic_sensor_current_threshold_amperes Current threshold of a board current sensor
+		# TYPE sonic_sensor_current_threshold_amperes gauge
+		# HELP sonic_sensor_voltage_threshold_volts Voltage threshold of a board voltage sensor
+		# TYPE sonic_sensor_voltage_threshold_volts gauge
+		# HELP sonic_sensor_voltage_volts Voltage reported by a board voltage sensor
+		# TYPE sonic_sensor_voltage_volts gauge
+	`
+
+	// VSENSOR1 is reported in V without thresholds, VSENSOR2 has no reading,
+	// ISENSOR1 has no high threshold
+	expected := `
+		sonic_sensor_current_amperes{sensor="ISENSOR0"} 15.5
+		sonic_sensor_current_amperes{sensor="ISENSOR1"} 2.5
+		sonic_sensor_current_threshold_amperes{sensor="ISENSOR0",threshold="critical_high"} 25
+		sonic_sensor_current_threshold_amperes{sensor="ISENSOR0",threshold="high"} 20
+		sonic_sensor_current_threshold_amperes{sensor="ISENSOR1",threshold="critical_high"} 4
+		sonic_sensor_voltage_threshold_volts{sensor="VSENSOR0",threshold="critical_high"} 1.4
+		sonic_sensor_voltage_threshold_volts{sensor="VSENSOR0",threshold="critical_low"} 1.1
+		sonic_sensor_voltage_threshold_volts{sensor="VSENSOR0",threshold="high"} 1.35
+		sonic_sensor_voltage_threshold_volts{sensor="VSENSOR0",threshold="low"} 1.15
+		sonic_sensor_voltage_volts{sensor="VSENSOR0"} 1.25
+		sonic_sensor_voltage_volts{sensor="VSENSOR1"} 12.1
+	`
+
+	if err := testutil.CollectAndCompare(sensorCollector, strings.NewReader(metadata+expected),
+		"sonic_sensor_current_amperes", "sonic_sensor_current_threshold_amperes",
+		"sonic_sensor_voltage_threshold_volts", "sonic_sensor_voltage_volts"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestCriticalProcessCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	redisServer.DB(6).HSet("PROCESS_STATS|4567", "UID", "300", "%CPU", "1.5", "CMD", "/usr/lib/frr/bgpd -A 127.0.0.1")
+	defer redisServer.DB(6).Del("PROCESS_STATS|4567")
+
+	criticalProcessCollector := NewCriticalProcessCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(criticalProcessCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_critical_process_up Whether a critical SONiC process is running: 0(DOWN), 1(UP)
+		# TYPE sonic_critical_process_up gauge
+	`
+
+	expected := `
+		sonic_critical_process_up{process="bgpd"} 1
+		sonic_critical_process_up{process="fpmsyncd"} 0
+		sonic_critical_process_up{process="neighsyncd"} 0
+		sonic_critical_process_up{process="orchagent"} 1
+		sonic_critical_process_up{process="portsyncd"} 0
+		sonic_critical_process_up{process="syncd"} 1
+		sonic_critical_process_up{process="teamd"} 0
+		sonic_critical_process_up{process="teamsyncd"} 0
+		sonic_critical_process_up{process="zebra"} 0
+	`
+
+	if err := testutil.CollectAndCompare(criticalProcessCollector, strings.NewReader(metadata+expected),
+		"sonic_critical_process_up"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	// bgp is disabled, teamd is enabled
+	redisServer.DB(4).HSet("FEATURE|bgp", "state", "disabled")
+	defer redisServer.DB(4).HSet("FEATURE|bgp", "state", "enabled")
+	redisServer.DB(4).HSet("FEATURE|teamd", "state", "enabled")
+	defer redisServer.DB(4).Del("FEATURE|teamd")
+
+	expected = `
+		sonic_critical_process_up{process="neighsyncd"} 0
+		sonic_critical_process_up{process="orchagent"} 1
+		sonic_critical_process_up{process="portsyncd"} 0
+		sonic_critical_process_up{process="syncd"} 1
+		sonic_critical_process_up{process="teamd"} 0
+		sonic_critical_process_up{process="teamsyncd"} 0
+	`
+
+	criticalProcessCollector = NewCriticalProcessCollector(logger, redisClient, testConfig)
+	if err := testutil.CollectAndCompare(criticalProcessCollector, strings.NewReader(metadata+expected),
+		"sonic_critical_process_up"); err != nil {
+		t.Errorf("unexpected collecting result with bgp disabled:\n%s", err)
+	}
+}
+
+func TestGearboxCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	gearboxCollector := NewGearboxCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(gearboxCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_gearbox_status Whether an external gearbox PHY reports its temperature: 0(DOWN), 1(UP)
+		# TYPE sonic_gearbox_status gauge
+		# HELP sonic_gearbox_temperature_celsius Temperature of an external gearbox PHY
+		# TYPE sonic_gearbox_temperature_celsius gauge
+		# HELP sonic_gearbox_collector_success Whether gearbox collector succeeded
+		# TYPE sonic_gearbox_collector_success gauge
+	`
+
+	// sesto-2 reports N/A
+	expected := `
+		sonic_gearbox_status{phy="sesto-1"} 1
+		sonic_gearbox_status{phy="sesto-2"} 0
+		sonic_gearbox_temperature_celsius{phy="sesto-1"} 52.5
+		sonic_gearbox_collector_success 1
+	`
+
+	if err := testutil.CollectAndCompare(gearboxCollector, strings.NewReader(metadata+expected),
+		"sonic_gearbox_status", "sonic_gearbox_temperature_celsius", "sonic_gearbox_collector_success"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestGearboxCollectorWithoutGearbox(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	redisServer.DB(0).Del("_GEARBOX_TABLE:phy:1")
+	redisServer.DB(0).Del("_GEARBOX_TABLE:phy:2")
+	defer redisServer.DB(0).HSet("_GEARBOX_TABLE:phy:1", "phy_id", "1", "name", "sesto-1", "address", "0x1000",
+		"lib_name", "libsai_phy_sesto-1.so", "firmware_path", "/tmp/phy-sesto-1.bin", "firmware_major_version", "v0.2")
+	defer redisServer.DB(0).HSet("_GEARBOX_TABLE:phy:2", "phy_id", "2", "name", "sesto-2", "address", "0x2000",
+		"lib_name", "libsai_phy_sesto-2.so")
+
+	gearboxCollector := NewGearboxCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_gearbox_collector_success Whether gearbox collector succeeded
+		# TYPE sonic_gearbox_collector_success gauge
+	`
+
+	expected := `
+		sonic_gearbox_collector_success 1
+	`
+
+	if err := testutil.CollectAndCompare(gearboxCollector, strings.NewReader(metadata+expected),
+		"sonic_gearbox_status", "sonic_gearbox_temperature_celsius", "sonic_gearbox_collector_success"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestFeatureCollector(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	featureCollector := NewFeatureCollector(logger, redisClient, testConfig)
+
+	problems, err := testutil.CollectAndLint(featureCollector)
+	if err != nil {
+		t.Error("metric lint completed with errors")
+	}
+
+	for _, problem := range problems {
+		t.Errorf("metric %v has a problem: %v", problem.Metric, problem.Text)
+	}
+
+	metadata := `
+		# HELP sonic_feature_state Whether a SONiC feature is admin enabled: 0(DISABLED), 1(ENABLED)
+		# TYPE sonic_feature_state gauge
+	`
+
+	// snmp has no auto_restart field
+	expected := `
+		sonic_feature_state{auto_restart="always_enabled",feature="swss"} 1
+		sonic_feature_state{auto_restart="disabled",feature="lldp"} 1
+		sonic_feature_state{auto_restart="disabled",feature="snmp"} 0
+		sonic_feature_state{auto_restart="enabled",feature="bgp"} 1
+		sonic_feature_state{auto_restart="enabled",feature="telemetry"} 0
+	`
+
+	if err := testutil.CollectAndCompare(featureCollector, strings.NewReader(metadata+expected),
+		"sonic_feature_state"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestFeatureServices(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	featureCollector := NewFeatureCollector(logger, redisClient, Config{CacheDuration: 0})
+
+	metadata := `
+		# HELP sonic_snmp_agent_up Whether the snmp feature is enabled and running: 0(DOWN), 1(UP)
+		# TYPE sonic_snmp_agent_up gauge
+		# HELP sonic_telemetry_up Whether the telemetry feature is enabled and running: 0(DOWN), 1(UP)
+		# TYPE sonic_telemetry_up gauge
+	`
+
+	compare := func(t *testing.T, expected string) {
+		t.Helper()
+		if err := testutil.CollectAndCompare(featureCollector, strings.NewReader(metadata+expected),
+			"sonic_snmp_agent_up", "sonic_telemetry_up"); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+
+	// both features are disabled in the fixtures
+	t.Run("disabled", func(t *testing.T) {
+		compare(t, `
+			sonic_snmp_agent_up 0
+			sonic_telemetry_up 0
+		`)
+	})
+
+	t.Run("enabled running", func(t *testing.T) {
+		redisServer.DB(4).HSet("FEATURE|snmp", "state", "enabled")
+		defer redisServer.DB(4).HSet("FEATURE|snmp", "state", "disabled")
+		redisServer.DB(6).HSet("FEATURE|snmp", "system_state", "up")
+		defer redisServer.DB(6).Del("FEATURE|snmp")
+
+		compare(t, `
+			sonic_snmp_agent_up 1
+			sonic_telemetry_up 0
+		`)
+	})
+
+	t.Run("enabled down", func(t *testing.T) {
+		redisServer.DB(4).HSet("FEATURE|telemetry", "state", "enabled")
+		defer redisServer.DB(4).HSet("FEATURE|telemetry", "state", "disabled")
+		redisServer.DB(6).HSet("FEATURE|telemetry", "system_state", "down")
+		defer redisServer.DB(6).Del("FEATURE|telemetry")
+		// the container state wins over a lingering process
+		redisServer.DB(6).HSet("PROCESS_STATS|9999", "CMD", "/usr/sbin/telemetry -logtostderr")
+		defer redisServer.DB(6).Del("PROCESS_STATS|9999")
+
+		compare(t, `
+			sonic_snmp_agent_up 0
+			sonic_telemetry_up 0
+		`)
+	})
+
+	// gnmi replaced the telemetry feature, older images don't record the container state
+	t.Run("enabled running without container state", func(t *testing.T) {
+		redisServer.DB(4).HSet("FEATURE|gnmi", "state", "enabled")
+		defer redisServer.DB(4).Del("FEATURE|gnmi")
+		redisServer.DB(6).HSet("PROCESS_STATS|9999", "CMD", "/usr/sbin/telemetry -logtostderr")
+		defer redisServer.DB(6).Del("PROCESS_STATS|9999")
+
+		compare(t, `
+			sonic_snmp_agent_up 0
+			sonic_telemetry_up 1
+		`)
+	})
+}
+
+func TestScrapedKeys(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	// countKeys counts the fixture keys of a database starting with prefix
+	countKeys := func(db int, prefix string) int {
+		count := 0
+		for _, key := range redisServer.DB(db).Keys() {
+			if strings.HasPrefix(key, prefix) {
+				count++
+			}
+		}
+		return count
+	}
+
+	metadata := `
+		# HELP sonic_hw_scraped_keys Number of redis keys read by the last scrape per key type
+		# TYPE sonic_hw_scraped_keys gauge
+	`
+
+	expected := fmt.Sprintf(`
+		sonic_hw_scraped_keys{type="chassis"} %d
+		sonic_hw_scraped_keys{type="fan"} %d
+		sonic_hw_scraped_keys{type="psu"} %d
+	`, countKeys(6, "CHASSIS_INFO|"), countKeys(6, "FAN_INFO|"), countKeys(6, "PSU_INFO|PSU"))
+
+	if err := testutil.CollectAndCompare(NewHwCollector(logger, redisClient, testConfig),
+		strings.NewReader(metadata+expected), "sonic_hw_scraped_keys"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	metadata = `
+		# HELP sonic_crm_scraped_keys Number of redis keys read by the last scrape per key type
+		# TYPE sonic_crm_scraped_keys gauge
+	`
+
+	expected = fmt.Sprintf(`
+		sonic_crm_scraped_keys{type="acl"} %d
+		sonic_crm_scraped_keys{type="stats"} 1
+	`, countKeys(2, "CRM:ACL_STATS:"))
+
+	if err := testutil.CollectAndCompare(NewCrmCollector(logger, redisClient, testConfig),
+		strings.NewReader(metadata+expected), "sonic_crm_scraped_keys"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestMetricsNamespace(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	tests := []struct {
+		namespace string
+		expected  string
+	}{
+		{namespace: "switch", expected: "switch_ntp_synced"},
+		{namespace: "", expected: "ntp_synced"},
+	}
+
+	for _, test := range tests {
+		ntpCollector := NewNtpCollector(logger, redisClient, Config{Namespace: &test.namespace})
+
+		metadata := fmt.Sprintf(`
+			# HELP %[1]s Whether the system clock is synchronized by NTP: 0(UNSYNCHRONIZED), 1(SYNCHRONIZED)
+			# TYPE %[1]s gauge
+		`, test.expected)
+
+		expected := fmt.Sprintf(`
+			%s 1
+		`, test.expected)
+
+		if err := testutil.CollectAndCompare(ntpCollector, strings.NewReader(metadata+expected), test.expected); err != nil {
+			t.Errorf("unexpected collecting result with namespace %q:\n%s", test.namespace, err)
+		}
+	}
+
+	for _, namespace := range []string{"sonic", "", "my_switch:"} {
+		if err := ValidateNamespace(namespace); err != nil {
+			t.Errorf("namespace %q should be valid: %v", namespace, err)
+		}
+	}
+
+	for _, namespace := range []string{"1sonic", "sonic-exporter", "sönic"} {
+		if err := ValidateNamespace(namespace); err == nil {
+			t.Errorf("namespace %q should be invalid", namespace)
+		}
+	}
+}
+
+func TestInterfaceFilter(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	interfaces := []string{"Ethernet0", "Ethernet1", "Ethernet4", "Ethernet48", "PortChannel01"}
+
+	tests := []struct {
+		include  string
+		exclude  string
+		expected []string
+	}{
+		{expected: interfaces},
+		{include: "^Ethernet4", expected: []string{"Ethernet4", "Ethernet48"}},
+		{exclude: "^PortChannel", expected: []string{"Ethernet0", "Ethernet1", "Ethernet4", "Ethernet48"}},
+		{include: "^Ethernet", exclude: "^Ethernet4", expected: []string{"Ethernet0", "Ethernet1"}},
+		{include: "^Ethernet0$", exclude: "^Ethernet0$", expected: nil},
+	}
+
+	for _, test := range tests {
+		config := Config{}
+		if test.include != "" {
+			config.InterfaceInclude = regexp.MustCompile(test.include)
+		}
+		if test.exclude != "" {
+			config.InterfaceExclude = regexp.MustCompile(test.exclude)
+		}
+
+		var included []string
+		for _, interfaceName := range interfaces {
+			if config.includeInterface(interfaceName) {
+				included = append(included, interfaceName)
+			}
+		}
+
+		if !reflect.DeepEqual(included, test.expected) {
+			t.Errorf("include %q exclude %q: got %v, expected %v", test.include, test.exclude, included, test.expected)
+		}
+	}
+
+	// Excluded interfaces yield no series at all
+	interfaceCollector := NewInterfaceCollector(logger, redisClient, Config{InterfaceExclude: regexp.MustCompile("^Ethernet0$")})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(interfaceCollector)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "device" && label.GetValue() == "Ethernet0" {
+					t.Errorf("excluded interface exported in %s", family.GetName())
+				}
+			}
+		}
+	}
+
+	if count, _ := testutil.GatherAndCount(registry, "sonic_interface_info"); count == 0 {
+		t.Errorf("expected series of the interfaces not excluded")
+	}
+}
+
+func TestHwPsuSerialLabel(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	// PSU 2 without serial falls back to its slot
+	redisServer.DB(6).HSet("PSU_INFO|PSU 2", "serial", "N/A")
+	defer redisServer.DB(6).HSet("PSU_INFO|PSU 2", "serial", "CNLOD00111111B")
+
+	hwCollector := NewHwCollector(logger, redisClient, Config{PsuSerialLabel: true})
+
+	metadata := `
+		# HELP sonic_hw_psu_operational_status PSU operational status: 0(DOWN), 1(UP)
+		# TYPE sonic_hw_psu_operational_status gauge
+		# HELP sonic_hw_psu_info Non-numeric data about PSU, value is always 1
+		# TYPE sonic_hw_psu_info gauge
+	`
+
+	// psu_info keeps relating slot and serial
+	expected := `
+		sonic_hw_psu_operational_status{serial="2"} 1
+		sonic_hw_psu_operational_status{serial="CNLOD00111111A"} 1
+		sonic_hw_psu_info{model="0V1FD0A00",model_name="",serial="CNLOD00111111A",slot="1"} 1
+		sonic_hw_psu_info{model="0V1FD0A00",model_name="",serial="N/A",slot="2"} 1
+	`
+
+	if err := testutil.CollectAndCompare(hwCollector, strings.NewReader(metadata+expected),
+		"sonic_hw_psu_operational_status", "sonic_hw_psu_info"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestParseFanName(t *testing.T) {
+	tests := []struct {
+		key    string
+		slot   string
+		name   string
+		parsed bool
+	}{
+		{key: "PSU1 Fan", slot: "PSU1", name: "Fan", parsed: true},
+		{key: "FanTray3-Fan2", slot: "FanTray3", name: "Fan2", parsed: true},
+		{key: "PSU 1 FAN 2", slot: "PSU1", name: "FAN 2", parsed: true},
+		{key: "fantray1_fan2", slot: "fantray1", name: "fan2", parsed: true},
+		{key: "Fantray 2 Fan 1", slot: "Fantray2", name: "Fan 1", parsed: true},
+		{key: "fan1", slot: "0", name: "fan1", parsed: true},
+		{key: "FAN 3", slot: "0", name: "FAN 3", parsed: true},
+		{key: "cpu_fan", slot: "0", name: "cpu_fan", parsed: false},
+	}
+
+	for _, test := range tests {
+		slot, name, parsed := parseFanName(test.key)
+		if slot != test.slot || name != test.name || parsed != test.parsed {
+			t.Errorf("parseFanName(%q) = %q, %q, %v, expected %q, %q, %v",
+				test.key, slot, name, parsed, test.slot, test.name, test.parsed)
+		}
+	}
+}
+
+func TestHwFanInfo(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	redisServer.DB(6).HSet("FAN_INFO|fan5", "presence", "True", "status", "True", "direction", "EXHAUST", "speed", "40")
+	redisServer.DB(6).HSet("FAN_INFO|PSU 3 FAN 1", "presence", "True", "status", "False", "direction", "N/A")
+	defer redisServer.DB(6).Del("FAN_INFO|fan5")
+	defer redisServer.DB(6).Del("FAN_INFO|PSU 3 FAN 1")
+
+	hwCollector := NewHwCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_hw_fan_info Non-numeric data about fan, value is always 1
+		# TYPE sonic_hw_fan_info gauge
+		# HELP sonic_hw_fan_operational_status Fan operational status: 0(DOWN), 1(UP)
+		# TYPE sonic_hw_fan_operational_status gauge
+	`
+
+	// PSU 3 FAN 1 has no direction
+	expected := `
+		sonic_hw_fan_info{direction="exhaust",name="fan5",slot="0"} 1
+		sonic_hw_fan_info{direction="intake",name="Fan",slot="PSU1"} 1
+		sonic_hw_fan_info{direction="intake",name="Fan",slot="PSU2"} 1
+		sonic_hw_fan_info{direction="intake",name="Fan1",slot="FanTray2"} 1
+		sonic_hw_fan_info{direction="intake",name="Fan2",slot="FanTray3"} 1
+		sonic_hw_fan_operational_status{name="FAN 1",slot="PSU3"} 0
+		sonic_hw_fan_operational_status{name="Fan",slot="PSU1"} 1
+		sonic_hw_fan_operational_status{name="Fan",slot="PSU2"} 1
+		sonic_hw_fan_operational_status{name="Fan1",slot="FanTray2"} 1
+		sonic_hw_fan_operational_status{name="Fan2",slot="FanTray3"} 1
+		sonic_hw_fan_operational_status{name="fan5",slot="0"} 1
+	`
+
+	if err := testutil.CollectAndCompare(hwCollector, strings.NewReader(metadata+expected),
+		"sonic_hw_fan_info", "sonic_hw_fan_operational_status"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestHwFanSpeedTarget(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	hwCollector := NewHwCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_hw_fan_speed_target_rpm Fan target RPM
+		# TYPE sonic_hw_fan_speed_target_rpm gauge
+		# HELP sonic_hw_fan_speed_tolerance_ratio Tolerated deviation of the fan RPM from its target as a ratio of the target
+		# TYPE sonic_hw_fan_speed_tolerance_ratio gauge
+	`
+
+	// The other fans report N/A
+	expected := `
+		sonic_hw_fan_speed_target_rpm{name="Fan1",slot="FanTray2"} 40
+		sonic_hw_fan_speed_tolerance_ratio{name="Fan1",slot="FanTray2"} 0.2
+	`
+
+	if err := testutil.CollectAndCompare(hwCollector, strings.NewReader(metadata+expected),
+		"sonic_hw_fan_speed_target_rpm", "sonic_hw_fan_speed_tolerance_ratio"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestHwPsuPower(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	// PSU 2 reports input power directly but neither output power nor output voltage and current
+	redisServer.DB(6).HSet("PSU_INFO|PSU 2", "input_power", "70.5")
+	defer redisServer.DB(6).HDel("PSU_INFO|PSU 2", "input_power")
+	redisServer.DB(6).HSet("PSU_INFO|PSU 2", "power", "N/A")
+	defer redisServer.DB(6).HSet("PSU_INFO|PSU 2", "power", "60.0")
+
+	hwCollector := NewHwCollector(logger, redisClient, Config{Precision: 2})
+
+	metadata := `
+		# HELP sonic_hw_psu_input_power_watts PSU input power, approximated from input voltage and current if not reported
+		# TYPE sonic_hw_psu_input_power_watts gauge
+		# HELP sonic_hw_psu_output_power_watts PSU output power, approximated from output voltage and current if not reported
+		# TYPE sonic_hw_psu_output_power_watts gauge
+	`
+
+	// PSU 1 input power is derived from 233.2V * 0.3A
+	expected := `
+		sonic_hw_psu_input_power_watts{slot="1"} 69.96
+		sonic_hw_psu_input_power_watts{slot="2"} 70.5
+		sonic_hw_psu_output_power_watts{slot="1"} 60
+	`
+
+	if err := testutil.CollectAndCompare(hwCollector, strings.NewReader(metadata+expected),
+		"sonic_hw_psu_input_power_watts", "sonic_hw_psu_output_power_watts"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestHwPsuTemperatureThreshold(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	redisServer.DB(6).HSet("PSU_INFO|PSU 1", "temp_threshold", "65.0")
+	defer redisServer.DB(6).HSet("PSU_INFO|PSU 1", "temp_threshold", "N/A")
+	redisServer.DB(6).HSet("PSU_INFO|PSU 2", "temp_threshold", "high")
+	defer redisServer.DB(6).HSet("PSU_INFO|PSU 2", "temp_threshold", "N/A")
+
+	hwCollector := NewHwCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_hw_psu_temperature_threshold_celsius PSU high temperature threshold
+		# TYPE sonic_hw_psu_temperature_threshold_celsius gauge
+	`
+
+	// PSU 2 threshold doesn't parse and is skipped
+	expected := `
+		sonic_hw_psu_temperature_threshold_celsius{slot="1"} 65
+	`
+
+	if err := testutil.CollectAndCompare(hwCollector, strings.NewReader(metadata+expected),
+		"sonic_hw_psu_temperature_threshold_celsius"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestCounterTypes(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		NewInterfaceCollector(logger, redisClient, testConfig),
+		NewCrmCollector(logger, redisClient, testConfig),
+		NewQueueCollector(logger, redisClient, testConfig),
+		NewCoppCollector(logger, redisClient, testConfig),
+		NewAclRuleCollector(logger, redisClient, testConfig),
+		NewVxlanCollector(logger, redisClient, testConfig),
+		NewDhcpRelayCollector(logger, redisClient, testConfig),
+		NewHwCollector(logger, redisClient, testConfig),
+	)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	types := map[string]dto.MetricType{}
+	for _, family := range families {
+		types[family.GetName()] = family.GetType()
+
+		// monotonic totals are counters, capacities and utilizations are gauges
+		if isCounter := family.GetType() == dto.MetricType_COUNTER; isCounter != strings.HasSuffix(family.GetName(), "_total") {
+			t.Errorf("%s has type %v", family.GetName(), family.GetType())
+		}
+	}
+
+	for name, expected := range map[string]dto.MetricType{
+		"sonic_interface_receive_bytes_total":                 dto.MetricType_COUNTER,
+		"sonic_queue_dropped_packets_total":                   dto.MetricType_COUNTER,
+		"sonic_dhcp_relay_packets_total":                      dto.MetricType_COUNTER,
+		"sonic_crm_resource_used":                             dto.MetricType_GAUGE,
+		"sonic_crm_resource_available":                        dto.MetricType_GAUGE,
+		"sonic_crm_acl_resource_used":                         dto.MetricType_GAUGE,
+		"sonic_interface_pfc_rx_pause_duration_seconds_total": dto.MetricType_COUNTER,
+	} {
+		if actual, ok := types[name]; !ok || actual != expected {
+			t.Errorf("%s: got type %v, want %v", name, actual, expected)
+		}
+	}
+}
+
+func TestHwPsuEfficiency(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	// PSU 2 reports no output power and has no efficiency
+	redisServer.DB(6).HSet("PSU_INFO|PSU 2", "power", "N/A")
+	defer redisServer.DB(6).HSet("PSU_INFO|PSU 2", "power", "60.0")
+
+	hwCollector := NewHwCollector(logger, redisClient, Config{Precision: 3})
+
+	metadata := `
+		# HELP sonic_hw_psu_efficiency_ratio PSU output power as a ratio of its input power
+		# TYPE sonic_hw_psu_efficiency_ratio gauge
+	`
+
+	// 60W output of 233.2V * 0.3A input
+	expected := `
+		sonic_hw_psu_efficiency_ratio{slot="1"} 0.858
+	`
+
+	if err := testutil.CollectAndCompare(hwCollector, strings.NewReader(metadata+expected), "sonic_hw_psu_efficiency_ratio"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	// an output exceeding the input is clamped
+	redisServer.DB(6).HSet("PSU_INFO|PSU 1", "power", "80.0")
+	defer redisServer.DB(6).HSet("PSU_INFO|PSU 1", "power", "60.0")
+
+	expected = `
+		sonic_hw_psu_efficiency_ratio{slot="1"} 1
+	`
+
+	if err := testutil.CollectAndCompare(NewHwCollector(logger, redisClient, Config{Precision: 3}),
+		strings.NewReader(metadata+expected), "sonic_hw_psu_efficiency_ratio"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestSanitizeLabelValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "CNLOD00111111A", expected: "CNLOD00111111A"},
+		{value: "  CNLOD00111111A \n", expected: "CNLOD00111111A"},
+		{value: "0V1FD0A00\x00\x00\x00", expected: "0V1FD0A00"},
+		{value: "PWR-500AC\r\nREV A", expected: "PWR-500AC REV A"},
+		{value: "S\xffN\xfe1", expected: "S\uFFFDN\uFFFD1"},
+		{value: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if sanitized := sanitizeLabelValue(tt.value); sanitized != tt.expected {
+			t.Errorf("sanitizeLabelValue(%q) = %q, want %q", tt.value, sanitized, tt.expected)
+		}
+	}
+}
+
+func TestHwInfoSanitized(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	redisServer.DB(6).HSet("PSU_INFO|PSU 1", "serial", "CNLOD00111111A \r\n")
+	defer redisServer.DB(6).HSet("PSU_INFO|PSU 1", "serial", "CNLOD00111111A")
+	redisServer.DB(6).HSet("CHASSIS_INFO|chassis 1", "model", "\tMODEL\x00\x001 ")
+	defer redisServer.DB(6).HSet("CHASSIS_INFO|chassis 1", "model", "006Y6V")
+
+	hwCollector := NewHwCollector(logger, redisClient, testConfig)
+
+	metadata := `
+		# HELP sonic_hw_psu_info Non-numeric data about PSU, value is always 1
+		# TYPE sonic_hw_psu_info gauge
+		# HELP sonic_hw_chassis_info Non-numeric data about chassis, value is always 1
+		# TYPE sonic_hw_chassis_info gauge
+	`
+
+	expected := `
+		sonic_hw_psu_info{model="0V1FD0A00",model_name="",serial="CNLOD00111111A",slot="1"} 1
+		sonic_hw_psu_info{model="0V1FD0A00",model_name="",serial="CNLOD00111111B",slot="2"} 1
+		sonic_hw_chassis_info{model="MODEL 1",name="chassis 1",psu_num="2",serial="123456"} 1
+	`
+
+	if err := testutil.CollectAndCompare(hwCollector, strings.NewReader(metadata+expected),
+		"sonic_hw_psu_info", "sonic_hw_chassis_info"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestScrapeOnce(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	hwCollector := NewHwCollector(logger, redisClient, testConfig)
+
+	metrics, err := hwCollector.ScrapeOnce(context.Background())
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+
+	if len(metrics) == 0 {
+		t.Errorf("expected metrics from scrape")
+	}
+
+	if !hwCollector.lastScrapeTime.IsZero() || len(hwCollector.cachedMetrics) != 0 {
+		t.Errorf("ScrapeOnce should not populate the cache")
+	}
+}
+
+func TestCollectorCacheDisabled(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	crmCollector := NewCrmCollector(logger, redisClient, Config{CacheDuration: 0})
+
+	for i := 0; i < 3; i++ {
+		commandsBefore := redisServer.CommandCount()
+
+		testutil.CollectAndCount(crmCollector)
+
+		if redisServer.CommandCount() == commandsBefore {
+			t.Errorf("collect %d was served from cache although caching is disabled", i)
+		}
+	}
+
+	cachedCollector := NewCrmCollector(logger, redisClient, testConfig)
+	testutil.CollectAndCount(cachedCollector)
+
+	commandsBefore := redisServer.CommandCount()
+	testutil.CollectAndCount(cachedCollector)
+	if redisServer.CommandCount() != commandsBefore {
+		t.Errorf("collect within cache duration should not issue redis commands")
+	}
+}
+
+func TestCacheWindow(t *testing.T) {
+	config := Config{
+		CacheDuration:    15 * time.Second,
+		CacheAdaptive:    true,
+		CacheMinDuration: time.Second,
+		CacheMaxDuration: time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		config   Config
+		interval time.Duration
+		expected time.Duration
+	}{
+		{name: "5s scrapes", config: config, interval: 5 * time.Second, expected: 2500 * time.Millisecond},
+		{name: "60s scrapes", config: config, interval: time.Minute, expected: 30 * time.Second},
+		{name: "bounded by max", config: config, interval: 5 * time.Minute, expected: time.Minute},
+		{name: "bounded by min", config: config, interval: time.Second, expected: time.Second},
+		{name: "fixed duration", config: Config{CacheDuration: 15 * time.Second}, interval: 5 * time.Second, expected: 15 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var window cacheWindow
+			now := time.Now()
+
+			// the cache duration is used until an interval has been observed
+			if cacheDuration := window.observe(now, tt.config); cacheDuration != tt.config.CacheDuration {
+				t.Errorf("first collect: got %v, want %v", cacheDuration, tt.config.CacheDuration)
+			}
+
+			for i := 1; i <= 3; i++ {
+				if cacheDuration := window.observe(now.Add(time.Duration(i)*tt.interval), tt.config); cacheDuration != tt.expected {
+					t.Errorf("collect %d: got %v, want %v", i, cacheDuration, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisServerInfoMetrics(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	redisCollector := NewRedisCollector(logger, redisClient, testConfig)
+
+	metrics, err := redisCollector.serverInfoMetrics("STATE_DB", map[string]string{
+		"redis_version":     "7.0.15",
+		"uptime_in_seconds": "3600",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(metrics) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(metrics))
+	}
+
+	var infoMetric, uptimeMetric dto.Metric
+	if err := metrics[0].Write(&infoMetric); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics[1].Write(&uptimeMetric); err != nil {
+		t.Fatal(err)
+	}
+
+	if infoMetric.GetGauge().GetValue() != 1 || infoMetric.GetLabel()[1].GetValue() != "7.0.15" {
+		t.Errorf("unexpected server info metric: %v", infoMetric.String())
+	}
+	if uptimeMetric.GetGauge().GetValue() != 3600 {
+		t.Errorf("unexpected uptime metric: %v", uptimeMetric.String())
+	}
+
+	if _, err := redisCollector.serverInfoMetrics("STATE_DB", map[string]string{"uptime_in_seconds": "soon"}); err == nil {
+		t.Errorf("expected parse error for invalid uptime")
+	}
+}
+
+// capturingHandler records the level of every message logged through it
+type capturingHandler struct {
+	mu     sync.Mutex
+	levels map[string]slog.Level
+}
+
+func (h *capturingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *capturingHandler) Handle(_ context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.levels[record.Message] = record.Level
+	return nil
+}
+
+func (h *capturingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *capturingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestCacheHitLogLevel(t *testing.T) {
+	handler := &capturingHandler{levels: map[string]slog.Level{}}
+	logger := slog.New(handler)
+
+	collectors := map[string]prometheus.Collector{
+		"hw":        NewHwCollector(logger, redisClient, testConfig),
+		"crm":       NewCrmCollector(logger, redisClient, testConfig),
+		"interface": NewInterfaceCollector(logger, redisClient, testConfig),
+	}
+
+	for name, collector := range collectors {
+		// the second collect is served from cache
+		testutil.CollectAndCount(collector)
+		testutil.CollectAndCount(collector)
+
+		message := "Returning " + name + " metrics from cache"
+		level, ok := handler.levels[message]
+		if !ok {
+			t.Errorf("expected %q to be logged", message)
+		} else if level != slog.LevelDebug {
+			t.Errorf("expected %q at %v, got %v", message, slog.LevelDebug, level)
+		}
+	}
+}
+
+func TestEmptyKeyspace(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	emptyClient, err := redis.NewTargetClient(s.Addr())
+	if err != nil {
+		t.Fatalf("failed to create redis client: %v", err)
+	}
+	defer emptyClient.Close()
+
+	var logs strings.Builder
+	logger := promslog.New(&promslog.Config{Writer: &logs})
+
+	expected := `
+		# HELP sonic_hw_collector_success Whether hw collector succeeded
+		# TYPE sonic_hw_collector_success gauge
+		sonic_hw_collector_success 1
+		# HELP sonic_hw_scraped_keys Number of redis keys read by the last scrape per key type
+		# TYPE sonic_hw_scraped_keys gauge
+		sonic_hw_scraped_keys{type="chassis"} 0
+		sonic_hw_scraped_keys{type="fan"} 0
+		sonic_hw_scraped_keys{type="psu"} 0
+		# HELP sonic_crm_collector_success Whether crm collector succeeded
+		# TYPE sonic_crm_collector_success gauge
+		sonic_crm_collector_success 1
+		# HELP sonic_crm_scraped_keys Number of redis keys read by the last scrape per key type
+		# TYPE sonic_crm_scraped_keys gauge
+		sonic_crm_scraped_keys{type="acl"} 0
+		sonic_crm_scraped_keys{type="stats"} 0
+	`
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewHwCollector(logger, emptyClient, testConfig), NewCrmCollector(logger, emptyClient, testConfig))
+
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"sonic_hw_collector_success", "sonic_hw_scraped_keys", "sonic_crm_collector_success", "sonic_crm_scraped_keys"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	for _, series := range []string{"sonic_hw_psu_info", "sonic_hw_fan_info", "sonic_hw_chassis_info", "sonic_crm_resource_used"} {
+		if count, err := testutil.GatherAndCount(registry, series); err != nil || count != 0 {
+			t.Errorf("expected no %s series, got %d, %v", series, count, err)
+		}
+	}
+
+	if strings.Contains(logs.String(), "level=ERROR") {
+		t.Errorf("empty keyspace should not be logged as error:\n%s", logs.String())
+	}
+}
+
+func TestCollectorFailureRecovery(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewHwCollector(logger, redisClient, Config{CacheDuration: 0}))
+
+	redisServer.SetError("LOADING redis is loading the dataset in memory")
+	err := testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP sonic_hw_collector_success Whether hw collector succeeded
+		# TYPE sonic_hw_collector_success gauge
+		sonic_hw_collector_success 0
+	`), "sonic_hw_collector_success")
+	redisServer.SetError("")
+	if err != nil {
+		t.Fatalf("unexpected result after failed scrape:\n%s", err)
+	}
+
+	// A pedantic registry fails gathering on duplicate series
+	for i := 0; i < 2; i++ {
+		if err := testutil.GatherAndCompare(registry, strings.NewReader(`
+			# HELP sonic_hw_collector_success Whether hw collector succeeded
+			# TYPE sonic_hw_collector_success gauge
+			sonic_hw_collector_success 1
+		`), "sonic_hw_collector_success"); err != nil {
+			t.Errorf("unexpected result after recovered scrape %d:\n%s", i, err)
+		}
+
+		count, err := testutil.GatherAndCount(registry, "sonic_hw_scrape_duration_seconds")
+		if err != nil {
+			t.Fatalf("gather failed: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected a single scrape duration series, got %d", count)
+		}
+	}
+}
+
+func TestCollectorKeepsCacheOnFailure(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	hwCollector := NewHwCollector(logger, redisClient, Config{CacheDuration: 0})
+
+	psuFanMetrics := []string{"sonic_hw_psu_input_voltage_volts", "sonic_hw_fan_rpm"}
+
+	goodCount := testutil.CollectAndCount(hwCollector, psuFanMetrics...)
+	if goodCount == 0 {
+		t.Fatalf("expected psu and fan metrics from a good scrape")
+	}
+
+	// A chassis key of the wrong type makes collectChassisInfo fail after
+	// psu and fan info have been collected
+	if err := redisServer.DB(6).Set("CHASSIS_INFO|chassis 2", "broken"); err != nil {
+		t.Fatal(err)
+	}
+	defer redisServer.DB(6).Del("CHASSIS_INFO|chassis 2")
+
+	if err := testutil.CollectAndCompare(hwCollector, strings.NewReader(`
+		# HELP sonic_hw_collector_success Whether hw collector succeeded
+		# TYPE sonic_hw_collector_success gauge
+		sonic_hw_collector_success 0
+	`), "sonic_hw_collector_success"); err != nil {
+		t.Errorf("unexpected result after failed scrape:\n%s", err)
+	}
+
+	if count := testutil.CollectAndCount(hwCollector, psuFanMetrics...); count != goodCount {
+		t.Errorf("failed scrape changed psu and fan metrics: got %d series, want %d", count, goodCount)
+	}
+}
+
+// lastScrapeTimestamp collects ntpCollector and returns its last scrape timestamp
+func lastScrapeTimestamp(t *testing.T, ntpCollector *ntpCollector) float64 {
+	t.Helper()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(ntpCollector)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() == "sonic_ntp_last_scrape_timestamp_seconds" {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+
+	t.Fatalf("sonic_ntp_last_scrape_timestamp_seconds not collected")
+	return 0
+}
+
+func TestCollectorLastScrapeTimestamp(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	ntpCollector := NewNtpCollector(logger, redisClient, Config{CacheDuration: time.Hour})
+
+	before := float64(time.Now().Unix())
+	if timestamp := lastScrapeTimestamp(t, ntpCollector); timestamp < before || timestamp > float64(time.Now().Unix()) {
+		t.Errorf("unexpected timestamp after first scrape: %v", timestamp)
+	}
+
+	// Serving from cache keeps the timestamp of the scrape that filled it
+	cacheTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+	ntpCollector.lastScrapeTime = cacheTime
+	if timestamp := lastScrapeTimestamp(t, ntpCollector); timestamp != float64(cacheTime.Unix()) {
+		t.Errorf("timestamp changed while serving cache: got %v, want %v", timestamp, cacheTime.Unix())
+	}
+
+	// A failed scrape keeps the timestamp of the last successful one
+	ntpCollector.config.CacheDuration = 0
+	redisServer.SetError("LOADING redis is loading the dataset in memory")
+	timestamp := lastScrapeTimestamp(t, ntpCollector)
+	redisServer.SetError("")
+	if timestamp != float64(cacheTime.Unix()) {
+		t.Errorf("timestamp changed by failed scrape: got %v, want %v", timestamp, cacheTime.Unix())
+	}
+
+	before = float64(time.Now().Unix())
+	if timestamp := lastScrapeTimestamp(t, ntpCollector); timestamp < before {
+		t.Errorf("timestamp did not advance on successful scrape: %v", timestamp)
+	}
+
+	if timestamp := scrapeTimestamp(time.Time{}); timestamp != 0 {
+		t.Errorf("expected 0 before the first successful scrape, got %v", timestamp)
+	}
+}
+
+func TestCollectorScrapeErrors(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	metadata := `
+		# HELP sonic_crm_scrape_errors_total Number of failed scrapes of sonic crm metrics per reason
+		# TYPE sonic_crm_scrape_errors_total counter
+	`
+
+	crmCollector := NewCrmCollector(logger, redisClient, Config{CacheDuration: 0})
+
+	redisServer.SetError("LOADING redis is loading the dataset in memory")
+	testutil.CollectAndCount(crmCollector)
+	redisServer.SetError("")
+
+	redisServer.DB(2).HSet("CRM:STATS", "crm_stats_fdb_entry_used", "garbage")
+	testutil.CollectAndCount(crmCollector)
+	redisServer.DB(2).HSet("CRM:STATS", "crm_stats_fdb_entry_used", "5")
+
+	// A successful scrape leaves the counters untouched
+	expected := `
+		sonic_crm_scrape_errors_total{reason="other"} 0
+		sonic_crm_scrape_errors_total{reason="parse"} 1
+		sonic_crm_scrape_errors_total{reason="redis_connect"} 0
+		sonic_crm_scrape_errors_total{reason="redis_read"} 1
+	`
+
+	if err := testutil.CollectAndCompare(crmCollector, strings.NewReader(metadata+expected),
+		"sonic_crm_scrape_errors_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	// A closed port refuses the connection
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.Close()
+
+	t.Setenv("REDIS_ADDRESS", listener.Addr().String())
+	closedClient, err := redis.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closedClient.Close()
+
+	unreachableCollector := NewCrmCollector(logger, closedClient, Config{Timeout: time.Second})
+
+	expected = `
+		sonic_crm_scrape_errors_total{reason="other"} 0
+		sonic_crm_scrape_errors_total{reason="parse"} 0
+		sonic_crm_scrape_errors_total{reason="redis_connect"} 1
+		sonic_crm_scrape_errors_total{reason="redis_read"} 0
+	`
+
+	if err := testutil.CollectAndCompare(unreachableCollector, strings.NewReader(metadata+expected),
+		"sonic_crm_scrape_errors_total"); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestScrapeErrorReason(t *testing.T) {
+	_, numErr := strconv.ParseFloat("garbage", 64)
+
+	tests := []struct {
+		err    error
+		reason string
+	}{
+		{err: fmt.Errorf("redis read failed: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), reason: "redis_connect"},
+		{err: fmt.Errorf("redis read failed: %w", context.DeadlineExceeded), reason: "redis_connect"},
+		{err: fmt.Errorf("value parse failed: %w", numErr), reason: "parse"},
+		{err: errors.New("malformed uptime file"), reason: "other"},
+	}
+
+	for _, test := range tests {
+		if reason := scrapeErrorReason(test.err); reason != test.reason {
+			t.Errorf("scrapeErrorReason(%v) = %s, expected %s", test.err, reason, test.reason)
+		}
+	}
+}
+
+func TestCollectorTimeout(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	// A stub redis server accepting connections without ever replying
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(io.Discard, conn)
+		}
+	}()
+
+	t.Setenv("REDIS_ADDRESS", listener.Addr().String())
+	stubClient, err := redis.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stubClient.Close()
+
+	crmCollector := NewCrmCollector(logger, stubClient, Config{Timeout: 100 * time.Millisecond})
+
+	start := time.Now()
+	err = testutil.CollectAndCompare(crmCollector, strings.NewReader(`
+		# HELP sonic_crm_collector_success Whether crm collector succeeded
+		# TYPE sonic_crm_collector_success gauge
+		sonic_crm_collector_success 0
+	`), "sonic_crm_collector_success")
+	if err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("scrape was not aborted by the timeout, took %v", elapsed)
+	}
+}
+
+func TestParseMeasurement(t *testing.T) {
+	tests := []struct {
+		input     string
+		value     float64
+		present   bool
+		malformed bool
+	}{
+		{input: "12.5", value: 12.5, present: true},
+		{input: "-3", value: -3, present: true},
+		{input: "12.5 C", value: 12.5, present: true},
+		{input: "230 V", value: 230, present: true},
+		{input: "3400 RPM", value: 3400, present: true},
+		{input: "45%", value: 45, present: true},
+		{input: " 1.2A ", value: 1.2, present: true},
+		{input: "N/A"},
+		{input: "n/a"},
+		{input: ""},
+		{input: "garbage", malformed: true},
+		{input: "12,5 C", malformed: true},
+		{input: "12.5 C extra", malformed: true},
+	}
+
+	for _, tt := range tests {
+		value, present, err := parseMeasurement(tt.input)
+		if (err != nil) != tt.malformed {
+			t.Errorf("%q: got error %v, want malformed %v", tt.input, err, tt.malformed)
+		}
+		if present != tt.present || value != tt.value {
+			t.Errorf("%q: got %v, %v, want %v, %v", tt.input, value, present, tt.value, tt.present)
+		}
+	}
+}
+
+func TestHwPsuMeasurementUnits(t *testing.T) {
+	promslogConfig := &promslog.Config{}
+	logger := promslog.New(promslogConfig)
+
+	hwCollector := NewHwCollector(logger, redisClient, testConfig)
+
+	redisServer.DB(6).HSet("PSU_INFO|PSU 3", "presence", "true", "status", "true",
+		"temp", "35.5 C", "input_voltage", "231 V", "input_current", "N/A", "output_voltage", "garbage")
+	defer redisServer.DB(6).Del("PSU_INFO|PSU 3")
+
+	metrics, err := hwCollector.collectPsuInfo(context.Background(), redisClient)
+	if err != nil {
+		t.Fatalf("failed to collect psu info: %v", err)
+	}
+
+	values := make(map[*prometheus.Desc]float64)
+	for _, metric := range metrics {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		if m.GetLabel()[0].GetValue() == "3" {
+			values[metric.Desc()] = m.GetGauge().GetValue()
+		}
+	}
+
+	if value, ok := values[hwCollector.hwPsuTemperatureCelsius]; !ok || value != 35.5 {
+		t.Errorf("got temperature %v, %v, want 35.5", value, ok)
+	}
+	if value, ok := values[hwCollector.hwPsuInputVoltageVolts]; !ok || value != 231 {
+		t.Errorf("got input voltage %v, %v, want 231", value, ok)
+	}
+	if _, ok := values[hwCollector.hwPsuInputCurrentAmperes]; ok {
+		t.Errorf("N/A input current should not be reported")
+	}
+	if _, ok := values[hwCollector.hwPsuOutputVoltageVolts]; ok {
+		t.Errorf("malformed output voltage should not be reported")
+	}
+	if _, ok := values[hwCollector.hwPsuOutputCurrentAmperes]; ok {
+		t.Errorf("missing output current should not be reported")
+	}
+}
+
+func TestDerivedGaugePrecision(t *testing.T) {
+	desc := prometheus.NewDesc("sonic_test_ratio", "Test ratio", nil, nil)
+
+	for precision, want := range map[int]float64{0: 0.123456789, 2: 0.12, 4: 0.1235} {
+		var metric dto.Metric
+		if err := derivedGauge(desc, 0.123456789, precision).Write(&metric); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := metric.GetGauge().GetValue(); got != want {
+			t.Errorf("precision %d: got %v, want %v", precision, got, want)
+		}
+	}
+}
+
+func TestResolveCountersByNameMap(t *testing.T) {
+	redisServer.DB(2).HSet("COUNTERS_TEST_NAME_MAP", "Ethernet0", "oid:0x9900000000001", "Ethernet4", "oid:0x9900000000002")
+	redisServer.DB(2).HSet("COUNTERS:oid:0x9900000000001", "SAI_TEST_STAT_PACKETS", "10", "SAI_TEST_STAT_BYTES", "640")
+	defer redisServer.DB(2).Del("COUNTERS_TEST_NAME_MAP")
+	defer redisServer.DB(2).Del("COUNTERS:oid:0x9900000000001")
+
+	counters, err := ResolveCountersByNameMap(context.Background(), redisClient, "COUNTERS_TEST_NAME_MAP")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Ethernet4 has no counters
+	expected := map[string]map[string]string{
+		"Ethernet0": {"SAI_TEST_STAT_PACKETS": "10", "SAI_TEST_STAT_BYTES": "640"},
+	}
+	if !reflect.DeepEqual(counters, expected) {
+		t.Errorf("got %v, want %v", counters, expected)
+	}
+
+	counters, err = ResolveCountersByNameMap(context.Background(), redisClient, "COUNTERS_MISSING_NAME_MAP")
+	if err != nil || len(counters) != 0 {
+		t.Errorf("missing name map should resolve to no counters, got %v, %v", counters, err)
+	}
+
+	redisServer.DB(2).Set("COUNTERS_STRING_NAME_MAP", "oid")
+	defer redisServer.DB(2).Del("COUNTERS_STRING_NAME_MAP")
+
+	if _, err := ResolveCountersByNameMap(context.Background(), redisClient, "COUNTERS_STRING_NAME_MAP"); err == nil {
+		t.Errorf("name map of the wrong type should fail")
+	}
+}
+
+func TestResolveNameMap(t *testing.T) {
+	redisServer.DB(2).HSet("COUNTERS_TEST_NAME_MAP", "Ethernet0:3", "oid:0x9900000000001", "Ethernet4:3", "oid:0x9900000000002", "Ethernet8", "oid:0x9900000000003")
+	redisServer.DB(2).HSet("PERSISTENT_WATERMARKS:oid:0x9900000000001", "SAI_TEST_STAT_WATERMARK_BYTES", "1024")
+	defer redisServer.DB(2).Del("COUNTERS_TEST_NAME_MAP")
+	defer redisServer.DB(2).Del("PERSISTENT_WATERMARKS:oid:0x9900000000001")
+
+	// Ethernet4 is filtered out, Ethernet8 has no index and no data
+	entries, err := resolveNameMap(context.Background(), redisClient, "COUNTERS_TEST_NAME_MAP", "PERSISTENT_WATERMARKS", func(port, _ string) bool {
+		return port != "Ethernet4"
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]nameMapEntry{
+		"Ethernet0:3": {oid: "oid:0x9900000000001", port: "Ethernet0", index: "3", fields: map[string]string{"SAI_TEST_STAT_WATERMARK_BYTES": "1024"}},
+		"Ethernet8":   {oid: "oid:0x9900000000003", port: "Ethernet8", index: "", fields: map[string]string{}},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("got %v, want %v", entries, expected)
+	}
+}
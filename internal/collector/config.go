@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// defaultNamespace is the prefix of the metric names unless overridden
+const defaultNamespace = "sonic"
+
+// namespacePattern matches the valid prefixes of a Prometheus metric name
+var namespacePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Config holds the settings shared by all collectors
+type Config struct {
+	// CacheDuration is how long scraped metrics are served from cache, 0 disables caching
+	CacheDuration time.Duration
+	// CacheAdaptive serves metrics from cache for half the observed interval between
+	// collects, bounded by CacheMinDuration and CacheMaxDuration, instead of CacheDuration
+	CacheAdaptive    bool
+	CacheMinDuration time.Duration
+	CacheMaxDuration time.Duration
+	// Timeout bounds the redis calls of a single scrape, 0 disables the deadline
+	Timeout time.Duration
+	// Precision is the number of decimal places derived gauges are rounded to, 0 keeps full precision
+	Precision int
+	// VersionFile is the path of SONiC's sonic_version.yml
+	VersionFile string
+	// UptimeFile is the path the system uptime is read from, empty disables the uptime
+	UptimeFile string
+	// Namespace is the prefix of the metric names, nil keeps sonic and an empty namespace drops the prefix
+	Namespace *string
+	// InterfaceInclude restricts interface series to the interfaces it matches, nil includes all
+	InterfaceInclude *regexp.Regexp
+	// InterfaceExclude drops the series of the interfaces it matches, it wins over InterfaceInclude
+	InterfaceExclude *regexp.Regexp
+	// PsuSerialLabel keys PSU series by serial instead of slot, PSUs without serial keep their slot
+	PsuSerialLabel bool
+	// WatermarkClear requests clearing the persistent buffer watermarks after every scrape,
+	// which also resets them for other consumers like watermarkstat
+	WatermarkClear bool
+}
+
+// ValidateNamespace returns an error if namespace can't prefix Prometheus metric
+// names. The empty namespace is valid and drops the prefix.
+func ValidateNamespace(namespace string) error {
+	if namespace != "" && !namespacePattern.MatchString(namespace) {
+		return fmt.Errorf("invalid metric namespace %q", namespace)
+	}
+
+	return nil
+}
+
+// cacheDuration returns how long scraped metrics are served from cache given the
+// observed interval between collects, 0 if no interval has been observed yet
+func (config Config) cacheDuration(interval time.Duration) time.Duration {
+	if !config.CacheAdaptive || interval <= 0 {
+		return config.CacheDuration
+	}
+
+	// Half the interval expires the cache before every regular scrape while
+	// serving scrapes of e.g. a HA pair of Prometheus servers from cache
+	return min(max(interval/2, config.CacheMinDuration), config.CacheMaxDuration)
+}
+
+// includeInterface reports whether the series of an interface are exported
+func (config Config) includeInterface(interfaceName string) bool {
+	if config.InterfaceExclude != nil && config.InterfaceExclude.MatchString(interfaceName) {
+		return false
+	}
+
+	return config.InterfaceInclude == nil || config.InterfaceInclude.MatchString(interfaceName)
+}
+
+// MetricNamespace returns the prefix of the metric names, the default one if
+// Namespace is not set
+func (config Config) MetricNamespace() string {
+	return config.namespace()
+}
+
+// namespace returns the prefix of the metric names
+func (config Config) namespace() string {
+	if config.Namespace == nil {
+		return defaultNamespace
+	}
+
+	return *config.Namespace
+}
+
+// scrapeContext returns the context redis calls of a single collect are issued with
+func (config Config) scrapeContext() (context.Context, context.CancelFunc) {
+	if config.Timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), config.Timeout)
+}
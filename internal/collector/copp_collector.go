@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type coppCollector struct {
+	*scrapeCache
+	coppGreenPackets *prometheus.Desc
+	coppRedPackets   *prometheus.Desc
+	coppRedBytes     *prometheus.Desc
+	redisClient      *redis.Client
+}
+
+func NewCoppCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *coppCollector {
+	const subsystem = "copp"
+	namespace := config.namespace()
+
+	collector := &coppCollector{
+		coppGreenPackets: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "green_packets_total"),
+			"Number of packets of a COPP trap group conforming to its policer", []string{"trap_group"}, nil),
+		coppRedPackets: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "red_packets_total"),
+			"Number of packets of a COPP trap group dropped by its policer", []string{"trap_group"}, nil),
+		coppRedBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "red_bytes_total"),
+			"Number of bytes of a COPP trap group dropped by its policer", []string{"trap_group"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "copp", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *coppCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.coppGreenPackets
+	ch <- collector.coppRedPackets
+	ch <- collector.coppRedBytes
+	collector.describeScrape(ch)
+}
+
+func (collector *coppCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	policerCountersMetrics, err := collector.collectPolicerCounters(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("copp policer counters collection failed: %w", err)
+	}
+	metrics = append(metrics, policerCountersMetrics...)
+
+	return metrics, nil
+}
+
+// collectPolicerCounters resolves the policers of the COPP trap groups through
+// COUNTERS_POLICER_NAME_MAP and reads their counters. Counters that cannot be
+// parsed are skipped.
+func (collector *coppCollector) collectPolicerCounters(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	policerCounters := map[*prometheus.Desc]string{
+		collector.coppGreenPackets: "SAI_POLICER_STAT_GREEN_PACKETS",
+		collector.coppRedPackets:   "SAI_POLICER_STAT_RED_PACKETS",
+		collector.coppRedBytes:     "SAI_POLICER_STAT_RED_BYTES",
+	}
+
+	policers, err := ResolveCountersByNameMap(ctx, redisClient, "COUNTERS_POLICER_NAME_MAP")
+	if err != nil {
+		return nil, err
+	}
+
+	for trapGroup, counters := range policers {
+		for desc, field := range policerCounters {
+			value, ok := counters[field]
+			if !ok {
+				continue
+			}
+
+			parsedValue, err := parseFloat(value)
+			if err != nil {
+				continue
+			}
+
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				desc, prometheus.CounterValue, parsedValue, trapGroup,
+			))
+		}
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// criticalProcess is a daemon and the SONiC feature whose container runs it
+type criticalProcess struct {
+	name    string
+	feature string
+}
+
+// criticalProcesses are the daemons the data and control plane depend on,
+// when one of them is gone the switch stops forwarding or converging
+var criticalProcesses = []criticalProcess{
+	{name: "syncd", feature: "syncd"},
+	{name: "orchagent", feature: "swss"},
+	{name: "portsyncd", feature: "swss"},
+	{name: "neighsyncd", feature: "swss"},
+	{name: "teamd", feature: "teamd"},
+	{name: "teamsyncd", feature: "teamd"},
+	{name: "bgpd", feature: "bgp"},
+	{name: "zebra", feature: "bgp"},
+	{name: "fpmsyncd", feature: "bgp"},
+}
+
+type criticalProcessCollector struct {
+	*scrapeCache
+	criticalProcessUp *prometheus.Desc
+	redisClient       *redis.Client
+}
+
+func NewCriticalProcessCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *criticalProcessCollector {
+	const subsystem = "critical_process"
+	namespace := config.namespace()
+
+	collector := &criticalProcessCollector{
+		criticalProcessUp: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "up"),
+			"Whether a critical SONiC process is running: 0(DOWN), 1(UP)", []string{"process"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "critical process", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *criticalProcessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.criticalProcessUp
+	collector.describeScrape(ch)
+}
+
+func (collector *criticalProcessCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	criticalProcessesMetrics, err := collector.collectCriticalProcesses(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("critical process collection failed: %w", err)
+	}
+	metrics = append(metrics, criticalProcessesMetrics...)
+
+	return metrics, nil
+}
+
+// collectCriticalProcesses reports every critical process as up when
+// procdockerstatsd lists a process of that name. Processes of features
+// disabled in FEATURE|<feature> of CONFIG_DB aren't expected to run and are
+// left out, processes of features without a FEATURE entry are reported.
+func (collector *criticalProcessCollector) collectCriticalProcesses(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	running, err := runningProcesses(ctx, redisClient)
+	if err != nil {
+		return nil, err
+	}
+
+	featureExpected := make(map[string]bool)
+	for _, process := range criticalProcesses {
+		expected, ok := featureExpected[process.feature]
+		if !ok {
+			data, err := redisClient.HgetAllFromDb(ctx, "CONFIG_DB", "FEATURE|"+process.feature)
+			if err != nil {
+				return nil, fmt.Errorf("redis read failed: %w", err)
+			}
+			expected = len(data) == 0 || featureEnabled(data)
+			featureExpected[process.feature] = expected
+		}
+		if !expected {
+			continue
+		}
+
+		up := 0.0
+		if running[process.name] {
+			up = 1
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.criticalProcessUp, prometheus.GaugeValue, up, process.name,
+		))
+	}
+
+	return metrics, nil
+}
+
+// runningProcesses returns the names of the processes procdockerstatsd lists in
+// PROCESS_STATS|<pid>, the name being the base name of the first word of the
+// command line.
+func runningProcesses(ctx context.Context, redisClient *redis.Client) (map[string]bool, error) {
+	const processKeyPattern string = "PROCESS_STATS|*"
+
+	processKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", processKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	processData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", processKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	running := make(map[string]bool)
+	for _, data := range processData {
+		command := strings.Fields(data["CMD"])
+		if len(command) == 0 {
+			continue
+		}
+		running[path.Base(command[0])] = true
+	}
+
+	return running, nil
+}
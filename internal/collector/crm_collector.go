@@ -5,33 +5,26 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
-	"sync"
-	"time"
 
 	"github.com/mwennrich/sonic-exporter/pkg/redis"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 type crmCollector struct {
+	*scrapeCache
 	crmResourceAvailable    *prometheus.Desc
 	crmResourceUsed         *prometheus.Desc
 	crmAclResourceAvailable *prometheus.Desc
 	crmAclResourceUsed      *prometheus.Desc
-	scrapeDuration          *prometheus.Desc
-	scrapeCollectorSuccess  *prometheus.Desc
-	cachedMetrics           []prometheus.Metric
-	lastScrapeTime          time.Time
-	logger                  *slog.Logger
-	mu                      sync.Mutex
+	crmScrapedKeys          *prometheus.Desc
+	redisClient             *redis.Client
 }
 
-func NewCrmCollector(logger *slog.Logger) *crmCollector {
-	const (
-		namespace = "sonic"
-		subsystem = "crm"
-	)
+func NewCrmCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *crmCollector {
+	const subsystem = "crm"
+	namespace := config.namespace()
 
-	return &crmCollector{
+	collector := &crmCollector{
 		crmResourceAvailable: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "resource_available"),
 			"Maximum available value for a resource", []string{"resource"}, nil),
 		crmResourceUsed: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "resource_used"),
@@ -40,12 +33,13 @@ func NewCrmCollector(logger *slog.Logger) *crmCollector {
 			"Maximum available value for an ACL resource", []string{"acl_target", "resource"}, nil),
 		crmAclResourceUsed: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "acl_resource_used"),
 			"Used value for an ACL resource", []string{"acl_target", "resource"}, nil),
-		scrapeDuration: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "scrape_duration_seconds"),
-			"Time it took for prometheus to scrape sonic crm metrics", nil, nil),
-		scrapeCollectorSuccess: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "collector_success"),
-			"Whether crm collector succeeded", nil, nil),
-		logger: logger,
+		crmScrapedKeys: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "scraped_keys"),
+			"Number of redis keys read by the last scrape per key type", []string{"type"}, nil),
+		redisClient: redisClient,
 	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "crm", collector.scrapeMetrics)
+
+	return collector
 }
 
 func (collector *crmCollector) Describe(ch chan<- *prometheus.Desc) {
@@ -53,138 +47,116 @@ func (collector *crmCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- collector.crmResourceUsed
 	ch <- collector.crmAclResourceAvailable
 	ch <- collector.crmAclResourceUsed
-	ch <- collector.scrapeDuration
-	ch <- collector.scrapeCollectorSuccess
+	ch <- collector.crmScrapedKeys
+	collector.describeScrape(ch)
 }
 
-func (collector *crmCollector) Collect(ch chan<- prometheus.Metric) {
-	const cacheDuration = 15 * time.Second
-
-	scrapeSuccess := 1.0
-
-	var ctx = context.Background()
-
-	collector.mu.Lock()
-	defer collector.mu.Unlock()
-
-	if time.Since(collector.lastScrapeTime) < cacheDuration {
-		// Return cached metrics without making redis calls
-		collector.logger.InfoContext(ctx, "Returning crm metrics from cache")
-
-		for _, metric := range collector.cachedMetrics {
-			ch <- metric
-		}
-		return
-	}
-
-	err := collector.scrapeMetrics(ctx)
-	if err != nil {
-		scrapeSuccess = 0
-		collector.logger.ErrorContext(ctx, err.Error())
-	}
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-		collector.scrapeCollectorSuccess, prometheus.GaugeValue, scrapeSuccess,
-	))
-
-	for _, cachedMetric := range collector.cachedMetrics {
-		ch <- cachedMetric
-	}
-}
+func (collector *crmCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
 
-func (collector *crmCollector) scrapeMetrics(ctx context.Context) error {
-	collector.logger.InfoContext(ctx, "Starting crm metric scrape")
-	scrapeTime := time.Now()
+	var metrics []prometheus.Metric
 
-	redisClient, err := redis.NewClient()
+	crmStats, err := redisClient.HgetAllFromDb(ctx, "COUNTERS_DB", "CRM:STATS")
 	if err != nil {
-		return fmt.Errorf("redis client initialization failed: %w", err)
+		return nil, fmt.Errorf("redis read failed: %w", err)
 	}
 
-	defer redisClient.Close()
-
-	// Reset metrics
-	collector.cachedMetrics = []prometheus.Metric{}
-
-	crmStats, err := redisClient.HgetAllFromDb(ctx, "COUNTERS_DB", "CRM:STATS")
-	if err != nil {
-		return fmt.Errorf("redis read failed: %w", err)
+	// A missing hash reads as empty, e.g. before the CRM polling interval passed after boot
+	crmStatsKeys := 0.0
+	if len(crmStats) > 0 {
+		crmStatsKeys = 1
+	} else {
+		collector.logger.DebugContext(ctx, "No crm stats found", "key", "CRM:STATS")
 	}
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.crmScrapedKeys, prometheus.GaugeValue, crmStatsKeys, "stats",
+	))
 
-	err = collector.collectCrmStatsCounters(crmStats)
+	crmStatsCountersMetrics, err := collector.collectCrmStatsCounters(crmStats)
 	if err != nil {
-		return fmt.Errorf("crm stats collection failed: %w", err)
+		return nil, fmt.Errorf("crm stats collection failed: %w", err)
 	}
+	metrics = append(metrics, crmStatsCountersMetrics...)
 
-	err = collector.collectCrmAclStats(ctx, redisClient)
+	crmAclStatsMetrics, err := collector.collectCrmAclStats(ctx, redisClient)
 	if err != nil {
-		return fmt.Errorf("crm acl stats collection failed: %w", err)
+		return nil, fmt.Errorf("crm acl stats collection failed: %w", err)
 	}
+	metrics = append(metrics, crmAclStatsMetrics...)
 
-	collector.logger.InfoContext(ctx, "Ending crm metric scrape")
-	collector.lastScrapeTime = time.Now()
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-		collector.scrapeDuration, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(),
-	))
-	return nil
+	return metrics, nil
 }
 
-func (collector *crmCollector) collectCrmStatsCounters(crmStats map[string]string) error {
+func (collector *crmCollector) collectCrmStatsCounters(crmStats map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
 	for stat, value := range crmStats {
 		parsedValue, err := parseFloat(value)
 		if err != nil {
-			return fmt.Errorf("value parse failed: %w", err)
+			return nil, fmt.Errorf("value parse failed: %w", err)
 		}
 
 		if strings.HasSuffix(stat, "available") {
 			label := strings.TrimSuffix(strings.TrimPrefix(stat, "crm_stats_"), "_available")
-			collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+			metrics = append(metrics, prometheus.MustNewConstMetric(
 				collector.crmResourceAvailable, prometheus.GaugeValue, parsedValue, label,
 			))
 		}
 
 		if strings.HasSuffix(stat, "used") {
 			label := strings.TrimSuffix(strings.TrimPrefix(stat, "crm_stats_"), "_used")
-			collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+			metrics = append(metrics, prometheus.MustNewConstMetric(
 				collector.crmResourceUsed, prometheus.GaugeValue, parsedValue, label,
 			))
 		}
 	}
 
-	return nil
+	return metrics, nil
 }
 
-func (collector *crmCollector) collectCrmAclStats(ctx context.Context, redisClient redis.Client) error {
+func (collector *crmCollector) collectCrmAclStats(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
 	crmAclKeys, err := redisClient.KeysFromDb(ctx, "COUNTERS_DB", "CRM:ACL_STATS:*")
 	if err != nil {
-		return fmt.Errorf("redis read failed: %w", err)
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+	if len(crmAclKeys) == 0 {
+		collector.logger.DebugContext(ctx, "No crm acl keys found", "pattern", "CRM:ACL_STATS:*")
+	}
+
+	crmAclData, err := redisClient.HgetAllPipelined(ctx, "COUNTERS_DB", crmAclKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
 	}
 
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.crmScrapedKeys, prometheus.GaugeValue, float64(len(crmAclKeys)), "acl",
+	))
+
 	for _, key := range crmAclKeys {
 		aclTarget := strings.ToLower(strings.Join(strings.Split(key, ":")[2:], "_"))
-		aclGroupStats, err := redisClient.HgetAllFromDb(ctx, "COUNTERS_DB", key)
-		if err != nil {
-			return fmt.Errorf("redis read failed: %w", err)
-		}
+		aclGroupStats := crmAclData[key]
 		for stat, value := range aclGroupStats {
 			parsedValue, err := parseFloat(value)
 			if err != nil {
-				return fmt.Errorf("value parse failed: %w", err)
+				return nil, fmt.Errorf("value parse failed: %w", err)
 			}
 
 			if strings.HasSuffix(stat, "available") {
 				label := strings.TrimSuffix(strings.TrimPrefix(stat, "crm_stats_"), "_available")
-				collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+				metrics = append(metrics, prometheus.MustNewConstMetric(
 					collector.crmAclResourceAvailable, prometheus.GaugeValue, parsedValue, aclTarget, label,
 				))
 			}
 
 			if strings.HasSuffix(stat, "used") {
 				label := strings.TrimSuffix(strings.TrimPrefix(stat, "crm_stats_"), "_used")
-				collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+				metrics = append(metrics, prometheus.MustNewConstMetric(
 					collector.crmAclResourceUsed, prometheus.GaugeValue, parsedValue, aclTarget, label,
 				))
 			}
 		}
 	}
-	return nil
+	return metrics, nil
 }
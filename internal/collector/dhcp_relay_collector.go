@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type dhcpRelayCollector struct {
+	*scrapeCache
+	dhcpRelayPackets *prometheus.Desc
+	redisClient      *redis.Client
+}
+
+func NewDhcpRelayCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *dhcpRelayCollector {
+	const subsystem = "dhcp_relay"
+	namespace := config.namespace()
+
+	collector := &dhcpRelayCollector{
+		dhcpRelayPackets: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "packets_total"),
+			"Number of DHCPv4 packets relayed on an interface per direction and message type", []string{"device", "direction", "type"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "dhcp relay", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *dhcpRelayCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.dhcpRelayPackets
+	collector.describeScrape(ch)
+}
+
+func (collector *dhcpRelayCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	dhcpRelayCountersMetrics, err := collector.collectDhcpRelayCounters(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("dhcp relay counter collection failed: %w", err)
+	}
+	metrics = append(metrics, dhcpRelayCountersMetrics...)
+
+	return metrics, nil
+}
+
+// collectDhcpRelayCounters reads the counters dhcpmon keeps per relay interface
+// in DHCPv4_COUNTER_TABLE|<interface>. The RX and TX fields each hold all
+// message type counters, e.g. {'Discover':'12','Offer':'12'}. Unparsable
+// counters are skipped.
+func (collector *dhcpRelayCollector) collectDhcpRelayCounters(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const counterKeyPattern string = "DHCPv4_COUNTER_TABLE|*"
+
+	directions := map[string]string{
+		"RX": "rx",
+		"TX": "tx",
+	}
+
+	counterKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", counterKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	counterData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", counterKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for counterKey, data := range counterData {
+		interfaceName := strings.TrimPrefix(counterKey, "DHCPv4_COUNTER_TABLE|")
+
+		for field, direction := range directions {
+			counters, ok := data[field]
+			if !ok {
+				continue
+			}
+
+			for messageType, value := range parseDhcpCounters(counters) {
+				parsedValue, err := parseFloat(value)
+				if err != nil {
+					continue
+				}
+
+				metrics = append(metrics, prometheus.MustNewConstMetric(
+					collector.dhcpRelayPackets, prometheus.CounterValue, parsedValue, interfaceName, direction, messageType,
+				))
+			}
+		}
+	}
+
+	return metrics, nil
+}
+
+// parseDhcpCounters parses the python dict representation dhcpmon stores the
+// counters of a direction in, {'Discover':'12','Offer':'12'}, into a map of
+// message type to count. Malformed entries are skipped.
+func parseDhcpCounters(str string) map[string]string {
+	counters := make(map[string]string)
+
+	str = strings.TrimSpace(str)
+	str = strings.TrimSuffix(strings.TrimPrefix(str, "{"), "}")
+
+	for _, entry := range strings.Split(str, ",") {
+		messageType, value, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+
+		messageType = strings.Trim(strings.TrimSpace(messageType), `'"`)
+		value = strings.Trim(strings.TrimSpace(value), `'"`)
+		if messageType == "" {
+			continue
+		}
+
+		counters[messageType] = value
+	}
+
+	return counters
+}
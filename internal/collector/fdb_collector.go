@@ -0,0 +1,120 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fdbCollector struct {
+	*scrapeCache
+	fdbTotalEntries *prometheus.Desc
+	fdbEntries      *prometheus.Desc
+	redisClient     *redis.Client
+}
+
+func NewFdbCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *fdbCollector {
+	const subsystem = "fdb"
+	namespace := config.namespace()
+
+	collector := &fdbCollector{
+		fdbTotalEntries: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "total_entries"),
+			"Number of entries in the FDB (MAC address table)", nil, nil),
+		fdbEntries: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "entries"),
+			"Number of FDB (MAC address table) entries of a VLAN", []string{"vlan"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "fdb", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *fdbCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.fdbTotalEntries
+	ch <- collector.fdbEntries
+	collector.describeScrape(ch)
+}
+
+func (collector *fdbCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	fdbEntriesMetrics, err := collector.collectFdbEntries(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("fdb collection failed: %w", err)
+	}
+	metrics = append(metrics, fdbEntriesMetrics...)
+
+	return metrics, nil
+}
+
+// fdbEntryKey is the JSON object identifying an FDB entry in its ASIC_DB key.
+// Older images carry the VLAN id, newer ones the oid of the bridge VLAN.
+type fdbEntryKey struct {
+	Vlan string `json:"vlan"`
+	Bvid string `json:"bvid"`
+}
+
+// collectFdbEntries counts the FDB entries programmed to the ASIC. The table
+// can be large, so keys are scanned and only VLAN objects are read.
+func (collector *fdbCollector) collectFdbEntries(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const fdbKeyPrefix string = "ASIC_STATE:SAI_OBJECT_TYPE_FDB_ENTRY:"
+
+	fdbKeys, err := redisClient.KeysFromDb(ctx, "ASIC_DB", fdbKeyPrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	totalEntries := 0
+	vlanEntries := make(map[string]int)
+	bvidVlans := make(map[string]string)
+
+	for _, fdbKey := range fdbKeys {
+		var entry fdbEntryKey
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(fdbKey, fdbKeyPrefix)), &entry); err != nil {
+			collector.logger.DebugContext(ctx, "Skipping malformed fdb entry", "key", fdbKey, "err", err)
+			continue
+		}
+
+		vlan := entry.Vlan
+		if vlan == "" && entry.Bvid != "" {
+			var ok bool
+			if vlan, ok = bvidVlans[entry.Bvid]; !ok {
+				vlanObject, err := redisClient.HgetAllFromDb(ctx, "ASIC_DB", fmt.Sprintf("ASIC_STATE:SAI_OBJECT_TYPE_VLAN:%s", entry.Bvid))
+				if err != nil {
+					return nil, fmt.Errorf("redis read failed: %w", err)
+				}
+
+				// Entries learned on a bridge port without a VLAN keep the oid
+				vlan = vlanObject["SAI_VLAN_ATTR_VLAN_ID"]
+				if vlan == "" {
+					vlan = entry.Bvid
+				}
+				bvidVlans[entry.Bvid] = vlan
+			}
+		}
+
+		totalEntries++
+		vlanEntries[vlan]++
+	}
+
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.fdbTotalEntries, prometheus.GaugeValue, float64(totalEntries),
+	))
+
+	for vlan, entries := range vlanEntries {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.fdbEntries, prometheus.GaugeValue, float64(entries), vlan,
+		))
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,194 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// featureService is an agent running in the container of a SONiC feature
+type featureService struct {
+	// name is the subsystem of the agent's up metric
+	name string
+	// features are the names of the feature across SONiC releases
+	features []string
+	// process is the agent's daemon as listed in PROCESS_STATS
+	process string
+}
+
+// featureServices are the agents reported as up or down
+var featureServices = []featureService{
+	{name: "snmp_agent", features: []string{"snmp"}, process: "snmpd"},
+	{name: "telemetry", features: []string{"telemetry", "gnmi"}, process: "telemetry"},
+}
+
+type featureCollector struct {
+	*scrapeCache
+	featureState *prometheus.Desc
+	serviceUp    map[string]*prometheus.Desc
+	redisClient  *redis.Client
+}
+
+func NewFeatureCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *featureCollector {
+	const subsystem = "feature"
+	namespace := config.namespace()
+
+	serviceUp := make(map[string]*prometheus.Desc, len(featureServices))
+	for _, service := range featureServices {
+		serviceUp[service.name] = prometheus.NewDesc(prometheus.BuildFQName(namespace, service.name, "up"),
+			fmt.Sprintf("Whether the %s feature is enabled and running: 0(DOWN), 1(UP)", service.features[0]), nil, nil)
+	}
+
+	collector := &featureCollector{
+		featureState: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "state"),
+			"Whether a SONiC feature is admin enabled: 0(DISABLED), 1(ENABLED)", []string{"feature", "auto_restart"}, nil),
+		serviceUp:   serviceUp,
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "feature", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *featureCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.featureState
+	for _, service := range featureServices {
+		ch <- collector.serviceUp[service.name]
+	}
+	collector.describeScrape(ch)
+}
+
+func (collector *featureCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	featuresMetrics, err := collector.collectFeatures(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("feature collection failed: %w", err)
+	}
+	metrics = append(metrics, featuresMetrics...)
+
+	servicesMetrics, err := collector.collectServices(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("feature service collection failed: %w", err)
+	}
+	metrics = append(metrics, servicesMetrics...)
+
+	return metrics, nil
+}
+
+// collectFeatures reads the features and their auto restart setting from
+// FEATURE|<feature> in CONFIG_DB. Features with state always_enabled count as
+// enabled, features without auto_restart are labeled disabled.
+func (collector *featureCollector) collectFeatures(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const featureKeyPattern string = "FEATURE|*"
+
+	featureKeys, err := redisClient.KeysFromDb(ctx, "CONFIG_DB", featureKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	featureData, err := redisClient.HgetAllPipelined(ctx, "CONFIG_DB", featureKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for featureKey, data := range featureData {
+		feature := strings.TrimPrefix(featureKey, "FEATURE|")
+
+		autoRestart := strings.ToLower(data["auto_restart"])
+		if autoRestart == "" {
+			autoRestart = "disabled"
+		}
+
+		enabled := 0.0
+		if featureEnabled(data) {
+			enabled = 1
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.featureState, prometheus.GaugeValue, enabled, feature, autoRestart,
+		))
+	}
+
+	return metrics, nil
+}
+
+// collectServices reports the agents of featureServices as up when one of their
+// features is enabled in FEATURE|<feature> of CONFIG_DB and running. The
+// container state is read from system_state of FEATURE|<feature> in STATE_DB,
+// images not recording it fall back to the agent's daemon in PROCESS_STATS.
+// Agents of disabled or unknown features are down.
+func (collector *featureCollector) collectServices(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	var featureKeys []string
+	for _, service := range featureServices {
+		for _, feature := range service.features {
+			featureKeys = append(featureKeys, "FEATURE|"+feature)
+		}
+	}
+
+	featureConfigs, err := redisClient.HgetAllPipelined(ctx, "CONFIG_DB", featureKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	featureStates, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", featureKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	// read only when needed
+	var running map[string]bool
+
+	for _, service := range featureServices {
+		up := 0.0
+
+		for _, feature := range service.features {
+			if !featureEnabled(featureConfigs["FEATURE|"+feature]) {
+				continue
+			}
+
+			if systemState, ok := featureStates["FEATURE|"+feature]["system_state"]; ok {
+				if strings.EqualFold(systemState, "up") {
+					up = 1
+				}
+				continue
+			}
+
+			if running == nil {
+				running, err = runningProcesses(ctx, redisClient)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if running[service.process] {
+				up = 1
+			}
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.serviceUp[service.name], prometheus.GaugeValue, up,
+		))
+	}
+
+	return metrics, nil
+}
+
+// featureEnabled returns whether the FEATURE entry of CONFIG_DB is admin enabled,
+// features with state always_enabled count as enabled
+func featureEnabled(data map[string]string) bool {
+	switch strings.ToLower(data["state"]) {
+	case "enabled", "always_enabled":
+		return true
+	}
+	return false
+}
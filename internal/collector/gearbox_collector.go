@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type gearboxCollector struct {
+	*scrapeCache
+	gearboxTemperature *prometheus.Desc
+	gearboxStatus      *prometheus.Desc
+	redisClient        *redis.Client
+}
+
+func NewGearboxCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *gearboxCollector {
+	const subsystem = "gearbox"
+	namespace := config.namespace()
+
+	collector := &gearboxCollector{
+		gearboxTemperature: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "temperature_celsius"),
+			"Temperature of an external gearbox PHY", []string{"phy"}, nil),
+		gearboxStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "status"),
+			"Whether an external gearbox PHY reports its temperature: 0(DOWN), 1(UP)", []string{"phy"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "gearbox", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *gearboxCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.gearboxTemperature
+	ch <- collector.gearboxStatus
+	collector.describeScrape(ch)
+}
+
+func (collector *gearboxCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	gearboxPhysMetrics, err := collector.collectGearboxPhys(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("gearbox collection failed: %w", err)
+	}
+	metrics = append(metrics, gearboxPhysMetrics...)
+
+	return metrics, nil
+}
+
+// collectGearboxPhys reads the external PHYs gearsyncd publishes in
+// _GEARBOX_TABLE:phy:<id> of APPL_DB and their temperature from
+// PHY_TEMPERATURE_INFO|<phy name> in STATE_DB. A PHY without a temperature
+// reading is reported down. Platforms without gearbox have no PHY entries and
+// yield no series.
+func (collector *gearboxCollector) collectGearboxPhys(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const phyKeyPattern string = "_GEARBOX_TABLE:phy:*"
+
+	phyKeys, err := redisClient.KeysFromDb(ctx, "APPL_DB", phyKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	phyData, err := redisClient.HgetAllPipelined(ctx, "APPL_DB", phyKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	var phyNames, temperatureKeys []string
+	for phyKey, data := range phyData {
+		phyName := data["name"]
+		if phyName == "" {
+			phyName = strings.TrimPrefix(phyKey, "_GEARBOX_TABLE:")
+		}
+
+		phyNames = append(phyNames, phyName)
+		temperatureKeys = append(temperatureKeys, fmt.Sprintf("PHY_TEMPERATURE_INFO|%s", phyName))
+	}
+
+	temperatures, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", temperatureKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for i, phyName := range phyNames {
+		temperatureData := temperatures[temperatureKeys[i]]
+
+		temperature, ok, err := parseMeasurement(temperatureData["temperature"])
+		if err != nil {
+			collector.logger.DebugContext(ctx, "Skipping malformed reading", "phy", phyName, "err", err)
+		}
+
+		status := 0.0
+		if ok {
+			status = 1
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.gearboxTemperature, prometheus.GaugeValue, temperature, phyName,
+			))
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.gearboxStatus, prometheus.GaugeValue, status, phyName,
+		))
+	}
+
+	return metrics, nil
+}
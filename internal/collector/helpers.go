@@ -1,6 +1,21 @@
 package collector
 
-import "strconv"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 func parseFloat(str string) (float64, error) {
 	if len(str) > 0 {
@@ -8,3 +23,318 @@ func parseFloat(str string) (float64, error) {
 	}
 	return 0, nil
 }
+
+// parseMeasurement parses a sensor reading such as "12.5", "12.5 C" or
+// "3400 RPM", ignoring a trailing unit. Readings SONiC reports as "N/A" or
+// leaves empty are not present and return false without an error, malformed
+// readings return an error.
+func parseMeasurement(str string) (float64, bool, error) {
+	str = strings.TrimSpace(str)
+	if str == "" || strings.EqualFold(str, "N/A") {
+		return 0, false, nil
+	}
+
+	number := strings.TrimSpace(strings.TrimRightFunc(str, func(r rune) bool {
+		return unicode.IsLetter(r) || r == '%' || r == '°'
+	}))
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("malformed measurement %q", str)
+	}
+
+	return value, true, nil
+}
+
+// sanitizeLabelValue cleans up a free-form string of the platform, like a serial
+// number, for use as label value. Invalid UTF-8 is replaced, runs of control
+// characters are collapsed into a single space and surrounding whitespace is trimmed.
+func sanitizeLabelValue(value string) string {
+	var sanitized strings.Builder
+	inControl := false
+
+	for _, r := range strings.ToValidUTF8(value, "\uFFFD") {
+		if unicode.IsControl(r) {
+			if !inControl {
+				sanitized.WriteRune(' ')
+			}
+			inControl = true
+			continue
+		}
+
+		inControl = false
+		sanitized.WriteRune(r)
+	}
+
+	return strings.TrimSpace(sanitized.String())
+}
+
+// nameMapEntry is an object of a COUNTERS_DB name map and its data
+type nameMapEntry struct {
+	// oid is the SAI object id the name maps to
+	oid string
+	// port and index are the parts of names like "Ethernet0:3" of queues and
+	// priority groups, port is the whole name if it has no index
+	port  string
+	index string
+	// fields are the fields of <table>:<oid>, empty if there are none
+	fields map[string]string
+}
+
+// resolveNameMap reads the COUNTERS_DB name map at nameMapKey, e.g.
+// COUNTERS_QUEUE_NAME_MAP mapping "Ethernet0:3" to the queue oid, and fetches
+// the hashes <table>:<oid> of all names keep accepts, all if keep is nil, in a
+// single pipelined round-trip. The entries are returned by name.
+func resolveNameMap(ctx context.Context, redisClient *redis.Client, nameMapKey, table string, keep func(port, index string) bool) (map[string]nameMapEntry, error) {
+	nameMap, err := redisClient.HgetAllFromDb(ctx, "COUNTERS_DB", nameMapKey)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	entries := make(map[string]nameMapEntry, len(nameMap))
+	keys := make([]string, 0, len(nameMap))
+	for name, oid := range nameMap {
+		port, index, _ := strings.Cut(name, ":")
+		if keep != nil && !keep(port, index) {
+			continue
+		}
+
+		entries[name] = nameMapEntry{oid: oid, port: port, index: index}
+		keys = append(keys, fmt.Sprintf("%s:%s", table, oid))
+	}
+
+	data, err := redisClient.HgetAllPipelined(ctx, "COUNTERS_DB", keys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for name, entry := range entries {
+		entry.fields = data[fmt.Sprintf("%s:%s", table, entry.oid)]
+		entries[name] = entry
+	}
+
+	return entries, nil
+}
+
+// ResolveCountersByNameMap reads the COUNTERS_DB name map at nameMapKey, e.g.
+// COUNTERS_POLICER_NAME_MAP mapping friendly names to oids, and fetches the
+// COUNTERS hashes of all oids in a single pipelined round-trip. The counter
+// fields are returned by friendly name, names without counters are left out.
+func ResolveCountersByNameMap(ctx context.Context, redisClient *redis.Client, nameMapKey string) (map[string]map[string]string, error) {
+	entries, err := resolveNameMap(ctx, redisClient, nameMapKey, "COUNTERS", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[string]map[string]string, len(entries))
+	for name, entry := range entries {
+		if len(entry.fields) > 0 {
+			counters[name] = entry.fields
+		}
+	}
+
+	return counters, nil
+}
+
+// derivedGauge returns a gauge for a value computed or converted by the exporter,
+// rounded to precision decimal places. A precision of 0 keeps full precision.
+func derivedGauge(desc *prometheus.Desc, value float64, precision int, labelValues ...string) prometheus.Metric {
+	if precision > 0 {
+		scale := math.Pow(10, float64(precision))
+		value = math.Round(value*scale) / scale
+	}
+
+	return prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labelValues...)
+}
+
+// scrapeTimestamp returns the Unix time of the last successful scrape, 0 if no
+// scrape has succeeded yet.
+func scrapeTimestamp(lastScrapeTime time.Time) float64 {
+	if lastScrapeTime.IsZero() {
+		return 0
+	}
+
+	return float64(lastScrapeTime.Unix())
+}
+
+// cacheWindow tracks the interval between the collects of a collector, which
+// the adaptive cache derives its duration from
+type cacheWindow struct {
+	lastCollect time.Time
+	interval    time.Duration
+}
+
+// observe records a collect at now and returns how long metrics are served from cache
+func (window *cacheWindow) observe(now time.Time, config Config) time.Duration {
+	if !window.lastCollect.IsZero() {
+		window.interval = now.Sub(window.lastCollect)
+	}
+	window.lastCollect = now
+
+	return config.cacheDuration(window.interval)
+}
+
+// scrapeErrorReasons are the reason labels of the scrape error counters
+var scrapeErrorReasons = []string{"redis_connect", "redis_read", "parse", "other"}
+
+// redisError is implemented by the errors redis replies with
+type redisError interface {
+	RedisError()
+}
+
+// scrapeErrorReason classifies the error of a failed scrape. Unreachable or
+// unresponsive redis is redis_connect, an error reply of redis is redis_read
+// and an unparsable value is parse.
+func scrapeErrorReason(err error) string {
+	var (
+		netErr   net.Error
+		redisErr redisError
+		numErr   *strconv.NumError
+	)
+
+	switch {
+	case errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return "redis_connect"
+	case errors.As(err, &redisErr):
+		return "redis_read"
+	case errors.As(err, &numErr):
+		return "parse"
+	default:
+		return "other"
+	}
+}
+
+// scrapeCache serves the metrics of a collector's scrapes from cache and keeps
+// the bookkeeping of the scrapes. Collectors embed it, provide the scrape of
+// their metrics and their own descs, and send the bookkeeping descs with
+// describeScrape.
+type scrapeCache struct {
+	// name is the collector's name in help texts and logs, e.g. "pfc watchdog"
+	name                   string
+	scrape                 func(ctx context.Context) ([]prometheus.Metric, error)
+	scrapeDuration         *prometheus.Desc
+	scrapeCollectorSuccess *prometheus.Desc
+	lastScrapeTimestamp    *prometheus.Desc
+	scrapeErrors           *prometheus.Desc
+	scrapeDurationSeconds  float64
+	scrapeSuccess          float64
+	scrapeErrorCounts      map[string]float64
+	cachedMetrics          []prometheus.Metric
+	config                 Config
+	lastScrapeTime         time.Time
+	cacheWindow            cacheWindow
+	logger                 *slog.Logger
+	mu                     sync.Mutex
+	// commit is called once a scrape is cached, collectors keeping state
+	// across scrapes apply the state of the scrape there
+	commit func()
+}
+
+func newScrapeCache(logger *slog.Logger, config Config, subsystem, name string, scrape func(ctx context.Context) ([]prometheus.Metric, error)) *scrapeCache {
+	namespace := config.namespace()
+
+	return &scrapeCache{
+		name:   name,
+		scrape: scrape,
+		scrapeDuration: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "scrape_duration_seconds"),
+			fmt.Sprintf("Time it took for prometheus to scrape sonic %s metrics", name), nil, nil),
+		scrapeCollectorSuccess: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "collector_success"),
+			fmt.Sprintf("Whether %s collector succeeded", name), nil, nil),
+		lastScrapeTimestamp: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "last_scrape_timestamp_seconds"),
+			fmt.Sprintf("Unix time of the last successful scrape of sonic %s metrics, 0 before the first one", name), nil, nil),
+		scrapeErrors: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "scrape_errors_total"),
+			fmt.Sprintf("Number of failed scrapes of sonic %s metrics per reason", name), []string{"reason"}, nil),
+		scrapeSuccess:     1,
+		scrapeErrorCounts: make(map[string]float64),
+		config:            config,
+		logger:            logger,
+	}
+}
+
+// describeScrape sends the descs of the scrape bookkeeping
+func (cache *scrapeCache) describeScrape(ch chan<- *prometheus.Desc) {
+	ch <- cache.scrapeDuration
+	ch <- cache.scrapeCollectorSuccess
+	ch <- cache.lastScrapeTimestamp
+	ch <- cache.scrapeErrors
+}
+
+func (cache *scrapeCache) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := cache.config.scrapeContext()
+	defer cancel()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if time.Since(cache.lastScrapeTime) < cache.cacheWindow.observe(time.Now(), cache.config) {
+		// Return cached metrics without making redis calls
+		cache.logger.DebugContext(ctx, fmt.Sprintf("Returning %s metrics from cache", cache.name))
+	} else {
+		scrapeTime := time.Now()
+		metrics, err := cache.scrapeLocked(ctx)
+		cache.scrapeDurationSeconds = time.Since(scrapeTime).Seconds()
+		if err != nil {
+			// Keep serving the last good scrape rather than partial data
+			cache.scrapeSuccess = 0
+			cache.scrapeErrorCounts[scrapeErrorReason(err)]++
+			cache.logger.ErrorContext(ctx, err.Error())
+		} else {
+			cache.scrapeSuccess = 1
+			cache.cachedMetrics = metrics
+			cache.lastScrapeTime = time.Now()
+			if cache.commit != nil {
+				cache.commit()
+			}
+		}
+	}
+
+	for _, cachedMetric := range cache.cachedMetrics {
+		ch <- cachedMetric
+	}
+
+	// Scrape bookkeeping is kept out of the cache and emitted fresh on every collect
+	ch <- prometheus.MustNewConstMetric(
+		cache.scrapeDuration, prometheus.GaugeValue, cache.scrapeDurationSeconds,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		cache.scrapeCollectorSuccess, prometheus.GaugeValue, cache.scrapeSuccess,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		cache.lastScrapeTimestamp, prometheus.GaugeValue, scrapeTimestamp(cache.lastScrapeTime),
+	)
+	for _, reason := range scrapeErrorReasons {
+		ch <- prometheus.MustNewConstMetric(
+			cache.scrapeErrors, prometheus.CounterValue, cache.scrapeErrorCounts[reason], reason,
+		)
+	}
+}
+
+// Healthy reports whether the last scrape succeeded, collectors that have not
+// scraped yet are considered healthy
+func (cache *scrapeCache) Healthy() bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return cache.scrapeSuccess == 1
+}
+
+// ScrapeOnce scrapes the collector's metrics from redis, bypassing and leaving the cache untouched
+func (cache *scrapeCache) ScrapeOnce(ctx context.Context) ([]prometheus.Metric, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return cache.scrapeLocked(ctx)
+}
+
+// scrapeLocked runs the collector's scrape, cache.mu must be held
+func (cache *scrapeCache) scrapeLocked(ctx context.Context) ([]prometheus.Metric, error) {
+	cache.logger.InfoContext(ctx, fmt.Sprintf("Starting %s metric scrape", cache.name))
+
+	metrics, err := cache.scrape(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.logger.InfoContext(ctx, fmt.Sprintf("Ending %s metric scrape", cache.name))
+	return metrics, nil
+}
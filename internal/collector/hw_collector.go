@@ -6,71 +6,104 @@ import (
 	"log/slog"
 	"regexp"
 	"strings"
-	"sync"
-	"time"
 
 	"github.com/mwennrich/sonic-exporter/pkg/redis"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+var (
+	fanSlotRegex        = regexp.MustCompile(`(?i)^(PSU\d+|Fantray\d+)[\s-](.+)$`)
+	fanIndexedSlotRegex = regexp.MustCompile(`(?i)^(PSU|Fantray)[\s_]*(\d+)[\s_-]+(fan[\s_]*\d+)$`)
+	bareFanRegex        = regexp.MustCompile(`(?i)^fan[\s_]*\d+$`)
+)
+
 type hwCollector struct {
+	*scrapeCache
 	hwPsuInfo                 *prometheus.Desc
 	hwPsuInputVoltageVolts    *prometheus.Desc
 	hwPsuInputCurrentAmperes  *prometheus.Desc
 	hwPsuOutputVoltageVolts   *prometheus.Desc
 	hwPsuOutputCurrentAmperes *prometheus.Desc
+	hwPsuInputPowerWatts      *prometheus.Desc
+	hwPsuOutputPowerWatts     *prometheus.Desc
+	hwPsuEfficiencyRatio      *prometheus.Desc
 	hwPsuOperationalStatus    *prometheus.Desc
 	hwPsuAvailableStatus      *prometheus.Desc
 	hwPsuTemperatureCelsius   *prometheus.Desc
+	hwPsuTemperatureThreshold *prometheus.Desc
+	hwPsuRuntimeSeconds       *prometheus.Desc
+	hwFanInfo                 *prometheus.Desc
 	hwFanRpm                  *prometheus.Desc
+	hwFanSpeedTargetRpm       *prometheus.Desc
+	hwFanSpeedToleranceRatio  *prometheus.Desc
 	hwFanOperationalStatus    *prometheus.Desc
 	hwFanAvailableStatus      *prometheus.Desc
+	hwFanRuntimeSeconds       *prometheus.Desc
 	hwChassisInfo             *prometheus.Desc
-	scrapeDuration            *prometheus.Desc
-	scrapeCollectorSuccess    *prometheus.Desc
-	cachedMetrics             []prometheus.Metric
-	lastScrapeTime            time.Time
-	logger                    *slog.Logger
-	mu                        sync.Mutex
+	hwScrapedKeys             *prometheus.Desc
+	redisClient               *redis.Client
 }
 
-func NewHwCollector(logger *slog.Logger) *hwCollector {
-	const (
-		namespace = "sonic"
-		subsystem = "hw"
-	)
+func NewHwCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *hwCollector {
+	const subsystem = "hw"
+	namespace := config.namespace()
+
+	// PSU series are keyed by serial instead of slot with PsuSerialLabel, psu_info relates both
+	psuLabels := []string{"slot"}
+	if config.PsuSerialLabel {
+		psuLabels = []string{"serial"}
+	}
 
-	return &hwCollector{
+	collector := &hwCollector{
 		hwPsuInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_info"),
 			"Non-numeric data about PSU, value is always 1", []string{"slot", "serial", "model_name", "model"}, nil),
 		hwPsuInputVoltageVolts: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_input_voltage_volts"),
-			"PSU input voltage", []string{"slot"}, nil),
+			"PSU input voltage", psuLabels, nil),
 		hwPsuInputCurrentAmperes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_input_current_amperes"),
-			"PSU input current", []string{"slot"}, nil),
+			"PSU input current", psuLabels, nil),
 		hwPsuOutputVoltageVolts: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_output_voltage_volts"),
-			"PSU output voltage", []string{"slot"}, nil),
+			"PSU output voltage", psuLabels, nil),
 		hwPsuOutputCurrentAmperes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_output_current_amperes"),
-			"PSU output current", []string{"slot"}, nil),
+			"PSU output current", psuLabels, nil),
+		hwPsuInputPowerWatts: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_input_power_watts"),
+			"PSU input power, approximated from input voltage and current if not reported", psuLabels, nil),
+		hwPsuOutputPowerWatts: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_output_power_watts"),
+			"PSU output power, approximated from output voltage and current if not reported", psuLabels, nil),
+		hwPsuEfficiencyRatio: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_efficiency_ratio"),
+			"PSU output power as a ratio of its input power", psuLabels, nil),
 		hwPsuOperationalStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_operational_status"),
-			"PSU operational status: 0(DOWN), 1(UP)", []string{"slot"}, nil),
+			"PSU operational status: 0(DOWN), 1(UP)", psuLabels, nil),
 		hwPsuAvailableStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_available_status"),
-			"PSU availability status: not plugged in - 0, plugged in - 1", []string{"slot"}, nil),
+			"PSU availability status: not plugged in - 0, plugged in - 1", psuLabels, nil),
 		hwPsuTemperatureCelsius: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_temperature_celsius"),
-			"PSU temperature", []string{"slot"}, nil),
+			"PSU temperature", psuLabels, nil),
+		hwPsuTemperatureThreshold: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_temperature_threshold_celsius"),
+			"PSU high temperature threshold", psuLabels, nil),
+		hwPsuRuntimeSeconds: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "psu_runtime_seconds"),
+			"PSU accumulated runtime as reported by the platform", psuLabels, nil),
+		hwFanInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "fan_info"),
+			"Non-numeric data about fan, value is always 1", []string{"name", "slot", "direction"}, nil),
 		hwFanRpm: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "fan_rpm"),
 			"Fan RPM", []string{"name", "slot"}, nil),
+		hwFanSpeedTargetRpm: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "fan_speed_target_rpm"),
+			"Fan target RPM", []string{"name", "slot"}, nil),
+		hwFanSpeedToleranceRatio: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "fan_speed_tolerance_ratio"),
+			"Tolerated deviation of the fan RPM from its target as a ratio of the target", []string{"name", "slot"}, nil),
 		hwFanOperationalStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "fan_operational_status"),
 			"Fan operational status: 0(DOWN), 1(UP)", []string{"name", "slot"}, nil),
 		hwFanAvailableStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "fan_available_status"),
 			"Fan availability status: not plugged in - 0, plugged in - 1", []string{"name", "slot"}, nil),
+		hwFanRuntimeSeconds: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "fan_runtime_seconds"),
+			"Fan accumulated runtime as reported by the platform", []string{"name", "slot"}, nil),
 		hwChassisInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "chassis_info"),
 			"Non-numeric data about chassis, value is always 1", []string{"name", "psu_num", "serial", "model"}, nil),
-		scrapeDuration: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "scrape_duration_seconds"),
-			"Time it took for prometheus to scrape sonic hw metrics", nil, nil),
-		scrapeCollectorSuccess: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "collector_success"),
-			"Whether hw collector succeeded", nil, nil),
-		logger: logger,
+		hwScrapedKeys: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "scraped_keys"),
+			"Number of redis keys read by the last scrape per key type", []string{"type"}, nil),
+		redisClient: redisClient,
 	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "hw", collector.scrapeMetrics)
+
+	return collector
 }
 
 func (collector *hwCollector) Describe(ch chan<- *prometheus.Desc) {
@@ -79,252 +112,369 @@ func (collector *hwCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- collector.hwPsuInputCurrentAmperes
 	ch <- collector.hwPsuOutputVoltageVolts
 	ch <- collector.hwPsuOutputCurrentAmperes
+	ch <- collector.hwPsuInputPowerWatts
+	ch <- collector.hwPsuOutputPowerWatts
+	ch <- collector.hwPsuEfficiencyRatio
 	ch <- collector.hwPsuOperationalStatus
 	ch <- collector.hwPsuAvailableStatus
 	ch <- collector.hwPsuTemperatureCelsius
+	ch <- collector.hwPsuTemperatureThreshold
+	ch <- collector.hwPsuRuntimeSeconds
+	ch <- collector.hwFanInfo
 	ch <- collector.hwFanRpm
+	ch <- collector.hwFanSpeedTargetRpm
+	ch <- collector.hwFanSpeedToleranceRatio
 	ch <- collector.hwFanOperationalStatus
 	ch <- collector.hwFanAvailableStatus
+	ch <- collector.hwFanRuntimeSeconds
 	ch <- collector.hwChassisInfo
-	ch <- collector.scrapeDuration
-	ch <- collector.scrapeCollectorSuccess
+	ch <- collector.hwScrapedKeys
+	collector.describeScrape(ch)
 }
 
-func (collector *hwCollector) Collect(ch chan<- prometheus.Metric) {
-	const cacheDuration = 15 * time.Second
-
-	scrapeSuccess := 1.0
-
-	var ctx = context.Background()
+func (collector *hwCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
 
-	collector.mu.Lock()
-	defer collector.mu.Unlock()
+	var metrics []prometheus.Metric
 
-	if time.Since(collector.lastScrapeTime) < cacheDuration {
-		// Return cached metrics without making redis calls
-		collector.logger.InfoContext(ctx, "Returning hw metrics from cache")
-
-		for _, metric := range collector.cachedMetrics {
-			ch <- metric
-		}
-		return
+	psuInfoMetrics, err := collector.collectPsuInfo(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("hw psu info collection failed: %w", err)
 	}
+	metrics = append(metrics, psuInfoMetrics...)
 
-	err := collector.scrapeMetrics(ctx)
+	fanInfoMetrics, err := collector.collectFanInfo(ctx, redisClient)
 	if err != nil {
-		scrapeSuccess = 0
-		collector.logger.ErrorContext(ctx, "Returning hw metrics from cache", "err", err)
+		return nil, fmt.Errorf("hw psu info collection failed: %w", err)
 	}
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-		collector.scrapeCollectorSuccess, prometheus.GaugeValue, scrapeSuccess,
-	))
+	metrics = append(metrics, fanInfoMetrics...)
 
-	for _, cachedMetric := range collector.cachedMetrics {
-		ch <- cachedMetric
-	}
-}
-
-func (collector *hwCollector) scrapeMetrics(ctx context.Context) error {
-	collector.logger.InfoContext(ctx, "Starting hw metric scrape")
-	scrapeTime := time.Now()
-
-	redisClient, err := redis.NewClient()
+	chassisInfoMetrics, err := collector.collectChassisInfo(ctx, redisClient)
 	if err != nil {
-		return fmt.Errorf("redis client initialization failed: %w", err)
+		return nil, fmt.Errorf("hw chassis info collection failed: %w", err)
 	}
+	metrics = append(metrics, chassisInfoMetrics...)
 
-	defer redisClient.Close()
+	return metrics, nil
+}
 
-	// Reset metrics
-	collector.cachedMetrics = []prometheus.Metric{}
+func (collector *hwCollector) collectPsuInfo(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
 
-	err = collector.collectPsuInfo(ctx, redisClient)
-	if err != nil {
-		return fmt.Errorf("hw psu info collection failed: %w", err)
-	}
+	const psuKeyPattern string = "PSU_INFO|PSU*"
 
-	err = collector.collectFanInfo(ctx, redisClient)
+	psuKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", psuKeyPattern)
 	if err != nil {
-		return fmt.Errorf("hw psu info collection failed: %w", err)
+		return nil, err
+	}
+	if len(psuKeys) == 0 {
+		// Not an error, e.g. pmon hasn't populated STATE_DB yet after boot
+		collector.logger.DebugContext(ctx, "No psu keys found", "pattern", psuKeyPattern)
 	}
 
-	err = collector.collectChassisInfo(ctx, redisClient)
+	psuData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", psuKeys)
 	if err != nil {
-		return fmt.Errorf("hw chassis info collection failed: %w", err)
+		return nil, err
 	}
 
-	collector.logger.InfoContext(ctx, "Ending hw metric scrape")
-
-	collector.lastScrapeTime = time.Now()
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-		collector.scrapeDuration, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(),
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.hwScrapedKeys, prometheus.GaugeValue, float64(len(psuKeys)), "psu",
 	))
-	return nil
-}
-
-func (collector *hwCollector) collectPsuInfo(ctx context.Context, redisClient redis.Client) error {
-	const psuKeyPattern string = "PSU_INFO|PSU*"
-
-	psuKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", psuKeyPattern)
-	if err != nil {
-		return err
-	}
 
 	for _, psuKey := range psuKeys {
 		available_status := 0.0
 		operational_status := 0.0
 		psuId := strings.Split(psuKey, " ")[1]
 
-		data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", psuKey)
-		if err != nil {
-			return err
-		}
+		data := psuData[psuKey]
 
-		serial := data["serial"]
-		modelName := data["name"]
-		model := data["model"]
+		serial := sanitizeLabelValue(data["serial"])
+		modelName := sanitizeLabelValue(data["name"])
+		model := sanitizeLabelValue(data["model"])
 
-		collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+		metrics = append(metrics, prometheus.MustNewConstMetric(
 			collector.hwPsuInfo, prometheus.GaugeValue, 1, psuId, serial, modelName, model,
 		))
 
+		psuLabel := psuId
+		if collector.config.PsuSerialLabel && serial != "" && strings.ToUpper(serial) != "N/A" {
+			psuLabel = serial
+		}
+
 		if strings.ToLower(data["status"]) == "true" {
 			operational_status = 1.0
 		}
-		collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-			collector.hwPsuOperationalStatus, prometheus.GaugeValue, operational_status, psuId,
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.hwPsuOperationalStatus, prometheus.GaugeValue, operational_status, psuLabel,
 		))
 
 		if strings.ToLower(data["presence"]) == "true" {
 			available_status = 1.0
 		}
-		collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-			collector.hwPsuAvailableStatus, prometheus.GaugeValue, available_status, psuId,
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.hwPsuAvailableStatus, prometheus.GaugeValue, available_status, psuLabel,
 		))
 
-		// voltage, amperage and temperature metrics are appended only if values can be parsed
-		inVolts, err := parseFloat(data["input_voltage"])
-		if err == nil {
-			collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-				collector.hwPsuInputVoltageVolts, prometheus.GaugeValue, inVolts, psuId,
+		// voltage, amperage and temperature metrics are appended only if values are present and can be parsed
+		if inVolts, ok := collector.parseMeasurement(ctx, data, "input_voltage", psuKey); ok {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.hwPsuInputVoltageVolts, prometheus.GaugeValue, inVolts, psuLabel,
+			))
+		}
+
+		if inAmperes, ok := collector.parseMeasurement(ctx, data, "input_current", psuKey); ok {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.hwPsuInputCurrentAmperes, prometheus.GaugeValue, inAmperes, psuLabel,
+			))
+		}
+
+		if outVolts, ok := collector.parseMeasurement(ctx, data, "output_voltage", psuKey); ok {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.hwPsuOutputVoltageVolts, prometheus.GaugeValue, outVolts, psuLabel,
 			))
 		}
 
-		inAmperes, err := parseFloat(data["input_current"])
-		if err == nil {
-			collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-				collector.hwPsuInputCurrentAmperes, prometheus.GaugeValue, inAmperes, psuId,
+		if outAmperes, ok := collector.parseMeasurement(ctx, data, "output_current", psuKey); ok {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.hwPsuOutputCurrentAmperes, prometheus.GaugeValue, outAmperes, psuLabel,
 			))
 		}
 
-		outVolts, err := parseFloat(data["output_voltage"])
-		if err == nil {
-			collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-				collector.hwPsuOutputVoltageVolts, prometheus.GaugeValue, outVolts, psuId,
+		inWatts, inOk := collector.psuPower(ctx, data, "input_power", "input_voltage", "input_current", psuKey)
+		if inOk {
+			metrics = append(metrics, derivedGauge(
+				collector.hwPsuInputPowerWatts, inWatts, collector.config.Precision, psuLabel,
 			))
 		}
 
-		outAmperes, err := parseFloat(data["output_current"])
-		if err == nil {
-			collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-				collector.hwPsuOutputCurrentAmperes, prometheus.GaugeValue, outAmperes, psuId,
+		outWatts, outOk := collector.psuPower(ctx, data, "power", "output_voltage", "output_current", psuKey)
+		if outOk {
+			metrics = append(metrics, derivedGauge(
+				collector.hwPsuOutputPowerWatts, outWatts, collector.config.Precision, psuLabel,
 			))
 		}
 
-		temp, err := parseFloat(data["temp"])
-		if err == nil {
-			collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-				collector.hwPsuTemperatureCelsius, prometheus.GaugeValue, temp, psuId,
+		// readings of both sides aren't taken at the same instant, which can push the ratio above 1
+		if inOk && outOk && inWatts > 0 {
+			metrics = append(metrics, derivedGauge(
+				collector.hwPsuEfficiencyRatio, min(max(outWatts/inWatts, 0), 1), collector.config.Precision, psuLabel,
+			))
+		}
+
+		if temp, ok := collector.parseMeasurement(ctx, data, "temp", psuKey); ok {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.hwPsuTemperatureCelsius, prometheus.GaugeValue, temp, psuLabel,
+			))
+		}
+
+		if tempThreshold, ok := collector.parseMeasurement(ctx, data, "temp_threshold", psuKey); ok {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.hwPsuTemperatureThreshold, prometheus.GaugeValue, tempThreshold, psuLabel,
+			))
+		}
+
+		if runtimeHours, ok := parseRuntimeHours(data); ok {
+			metrics = append(metrics, derivedGauge(
+				collector.hwPsuRuntimeSeconds, runtimeHours*3600, collector.config.Precision, psuLabel,
 			))
 		}
 	}
 
-	return nil
+	return metrics, nil
 }
 
-func (collector *hwCollector) collectFanInfo(ctx context.Context, redisClient redis.Client) error {
+func (collector *hwCollector) collectFanInfo(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
 	const fanKeyPattern string = "FAN_INFO|*"
-	fanRegex := regexp.MustCompile(`(?i)FAN_INFO\|(PSU\d+|Fantray\d+)(\s|\-)(.+)`)
 
 	fanKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", fanKeyPattern)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(fanKeys) == 0 {
+		// Not an error, e.g. pmon hasn't populated STATE_DB yet after boot
+		collector.logger.DebugContext(ctx, "No fan keys found", "pattern", fanKeyPattern)
 	}
 
+	fanData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", fanKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.hwScrapedKeys, prometheus.GaugeValue, float64(len(fanKeys)), "fan",
+	))
+
 	for _, fanKey := range fanKeys {
 		// initialize default values
 		available_status := 0.0
 		operational_status := 0.0
-		fanSlot := "0"
-		fanName := strings.Split(fanKey, "|")[1]
 
 		// try to parse fan slot and name from redis key
-		if fanRegex.MatchString(fanKey) {
-			fanSlot = fanRegex.FindStringSubmatch(fanKey)[1]
-			fanName = fanRegex.FindStringSubmatch(fanKey)[3]
+		fanSlot, fanName, ok := parseFanName(strings.TrimPrefix(fanKey, "FAN_INFO|"))
+		if !ok {
+			collector.logger.DebugContext(ctx, "Unknown fan naming, using slot 0", "key", fanKey)
 		}
 
-		data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", fanKey)
-		if err != nil {
-			return err
-		}
+		data := fanData[fanKey]
 
 		// try to find fan slot name from data
 		if value, ok := data["drawer_name"]; ok {
 			if value != "N/A" {
-				fanSlot = value
+				fanSlot = sanitizeLabelValue(value)
 			}
 		}
 
+		if direction := sanitizeLabelValue(data["direction"]); direction != "" && direction != "N/A" {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.hwFanInfo, prometheus.GaugeValue, 1, fanName, fanSlot, strings.ToLower(direction),
+			))
+		}
+
 		if strings.ToLower(data["status"]) == "true" {
 			operational_status = 1.0
 		}
-		collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+		metrics = append(metrics, prometheus.MustNewConstMetric(
 			collector.hwFanOperationalStatus, prometheus.GaugeValue, operational_status, fanName, fanSlot,
 		))
 
 		if strings.ToLower(data["presence"]) == "true" {
 			available_status = 1.0
 		}
-		collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+		metrics = append(metrics, prometheus.MustNewConstMetric(
 			collector.hwFanAvailableStatus, prometheus.GaugeValue, available_status, fanName, fanSlot,
 		))
 
-		fanRpm, err := parseFloat(data["speed"])
-		if err == nil {
-			collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+		if fanRpm, ok := collector.parseMeasurement(ctx, data, "speed", fanKey); ok {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
 				collector.hwFanRpm, prometheus.GaugeValue, fanRpm, fanName, fanSlot,
 			))
 		}
+
+		if targetRpm, ok := collector.parseMeasurement(ctx, data, "speed_target", fanKey); ok {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.hwFanSpeedTargetRpm, prometheus.GaugeValue, targetRpm, fanName, fanSlot,
+			))
+		}
+
+		// speed_tolerance is reported in percent of the target
+		if tolerancePercent, ok := collector.parseMeasurement(ctx, data, "speed_tolerance", fanKey); ok {
+			metrics = append(metrics, derivedGauge(
+				collector.hwFanSpeedToleranceRatio, tolerancePercent/100, collector.config.Precision, fanName, fanSlot,
+			))
+		}
+
+		if runtimeHours, ok := parseRuntimeHours(data); ok {
+			metrics = append(metrics, derivedGauge(
+				collector.hwFanRuntimeSeconds, runtimeHours*3600, collector.config.Precision, fanName, fanSlot,
+			))
+		}
+	}
+
+	return metrics, nil
+}
+
+// parseFanName returns the slot and name of a fan from the FAN_INFO key of the
+// fan. It understands
+//   - fans of a slot, PSU1 Fan1 or FanTray3-Fan2
+//   - fans of a slot with separated index, PSU 1 FAN 2 or fantray1_fan2
+//   - fans without slot, fan1, reported in slot 0
+//
+// Other names are returned unparsed in slot 0 and false.
+func parseFanName(key string) (string, string, bool) {
+	if match := fanSlotRegex.FindStringSubmatch(key); match != nil {
+		return match[1], match[2], true
+	}
+
+	if match := fanIndexedSlotRegex.FindStringSubmatch(key); match != nil {
+		return match[1] + match[2], match[3], true
+	}
+
+	if bareFanRegex.MatchString(key) {
+		return "0", key, true
 	}
 
-	return nil
+	return "0", key, false
 }
 
-func (collector *hwCollector) collectChassisInfo(ctx context.Context, redisClient redis.Client) error {
+// parseMeasurement parses the reading in field of key, readings that are not
+// present are skipped silently and malformed ones are logged
+func (collector *hwCollector) parseMeasurement(ctx context.Context, data map[string]string, field, key string) (float64, bool) {
+	value, ok, err := parseMeasurement(data[field])
+	if err != nil {
+		collector.logger.DebugContext(ctx, "Skipping malformed reading", "key", key, "field", field, "err", err)
+	}
+
+	return value, ok
+}
+
+// psuPower returns the power reported in powerField, or approximates it as the
+// product of voltage and current for platforms that don't report it.
+func (collector *hwCollector) psuPower(ctx context.Context, data map[string]string, powerField, voltageField, currentField, key string) (float64, bool) {
+	if watts, ok := collector.parseMeasurement(ctx, data, powerField, key); ok {
+		return watts, true
+	}
+
+	volts, ok := collector.parseMeasurement(ctx, data, voltageField, key)
+	if !ok {
+		return 0, false
+	}
+	amperes, ok := collector.parseMeasurement(ctx, data, currentField, key)
+	if !ok {
+		return 0, false
+	}
+
+	return volts * amperes, true
+}
+
+// parseRuntimeHours returns the runtime hours some platforms track for PSUs and
+// fans. Platforms that don't track it have no runtime_hours field.
+func parseRuntimeHours(data map[string]string) (float64, bool) {
+	runtimeHours, ok, err := parseMeasurement(data["runtime_hours"])
+	if err != nil || !ok {
+		return 0, false
+	}
+
+	return runtimeHours, true
+}
+
+func (collector *hwCollector) collectChassisInfo(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
 	const chassisKeyPattern string = "CHASSIS_INFO|*"
 
 	chasisKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", chassisKeyPattern)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(chasisKeys) == 0 {
+		// Not an error, e.g. pmon hasn't populated STATE_DB yet after boot
+		collector.logger.DebugContext(ctx, "No chassis keys found", "pattern", chassisKeyPattern)
+	}
+
+	chassisData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", chasisKeys)
+	if err != nil {
+		return nil, err
 	}
 
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.hwScrapedKeys, prometheus.GaugeValue, float64(len(chasisKeys)), "chassis",
+	))
+
 	for _, chassisKey := range chasisKeys {
 		chassisId := strings.Split(chassisKey, "|")[1]
 
-		data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", chassisKey)
-		if err != nil {
-			return err
-		}
+		data := chassisData[chassisKey]
 
-		psuNum := data["psu_num"]
-		serial := data["serial"]
-		model := data["model"]
+		psuNum := sanitizeLabelValue(data["psu_num"])
+		serial := sanitizeLabelValue(data["serial"])
+		model := sanitizeLabelValue(data["model"])
 
-		collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+		metrics = append(metrics, prometheus.MustNewConstMetric(
 			collector.hwChassisInfo, prometheus.GaugeValue, 1, chassisId, psuNum, serial, model,
 		))
 	}
 
-	return nil
+	return metrics, nil
 }
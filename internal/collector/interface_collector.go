@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"regexp"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/mwennrich/sonic-exporter/pkg/redis"
@@ -15,12 +15,27 @@ import (
 
 type packetSize string
 
+// counterSeriesKey identifies a single counter series by its descriptor and label values.
+type counterSeriesKey struct {
+	desc   *prometheus.Desc
+	labels string
+}
+
+// counterSeries tracks when a counter series was created (first seen or last reset).
+type counterSeries struct {
+	created time.Time
+	value   float64
+}
+
 type interfaceCollector struct {
+	*scrapeCache
 	interfaceInfo                    *prometheus.Desc
 	interfaceMtu                     *prometheus.Desc
 	interfaceSpeed                   *prometheus.Desc
 	interfaceAdminStatus             *prometheus.Desc
 	interfaceOperationslStatus       *prometheus.Desc
+	interfaceOperStatusChanges       *prometheus.Desc
+	interfaceLastOperChange          *prometheus.Desc
 	interfaceTransceiverTemperature  *prometheus.Desc
 	interfaceTransceiverVoltage      *prometheus.Desc
 	interfaceOpticTransmitPower      *prometheus.Desc
@@ -33,21 +48,26 @@ type interfaceCollector struct {
 	interfaceReceivePackets          *prometheus.Desc
 	interfaceReceivedBytes           *prometheus.Desc
 	interfaceReceiveErrs             *prometheus.Desc
-	scrapeDuration                   *prometheus.Desc
-	scrapeCollectorSuccess           *prometheus.Desc
-	cachedMetrics                    []prometheus.Metric
-	lastScrapeTime                   time.Time
-	logger                           *slog.Logger
-	mu                               sync.Mutex
+	interfacePfcRxPauseDuration      *prometheus.Desc
+	interfacePfcRxPackets            *prometheus.Desc
+	interfacePfcTxPackets            *prometheus.Desc
+	interfaceFecCorrectedBits        *prometheus.Desc
+	interfaceFecUncorrectableFrames  *prometheus.Desc
+	interfaceBreakoutInfo            *prometheus.Desc
+	redisClient                      *redis.Client
+	// counterSeries are the series of the last cached scrape, a scrape tracks
+	// the series it sees in nextCounterSeries and they replace counterSeries
+	// only once the scrape is cached, so failed scrapes and ScrapeOnce leave
+	// them untouched and series of vanished interfaces are forgotten
+	counterSeries     map[counterSeriesKey]counterSeries
+	nextCounterSeries map[counterSeriesKey]counterSeries
 }
 
-func NewInterfaceCollector(logger *slog.Logger) *interfaceCollector {
-	const (
-		namespace = "sonic"
-		subsystem = "interface"
-	)
+func NewInterfaceCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *interfaceCollector {
+	const subsystem = "interface"
+	namespace := config.namespace()
 
-	return &interfaceCollector{
+	collector := &interfaceCollector{
 		interfaceInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "info"),
 			"Non-numeric data about interface, value is always 1", []string{"device", "alias", "index", "description"}, nil),
 		interfaceMtu: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "mtu_bytes"),
@@ -58,6 +78,10 @@ func NewInterfaceCollector(logger *slog.Logger) *interfaceCollector {
 			"Network device administrative status: 0(DOWN), 1(UP)", []string{"device"}, nil),
 		interfaceOperationslStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "operational_status"),
 			"Network device operational status:  0(DOWN), 1(UP)", []string{"device"}, nil),
+		interfaceOperStatusChanges: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "oper_status_changes_total"),
+			"Number of operational status changes of an interface", []string{"device"}, nil),
+		interfaceLastOperChange: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "last_oper_change_timestamp_seconds"),
+			"Unix time of the last operational status change of an interface", []string{"device"}, nil),
 		interfaceTransceiverTemperature: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "transceiver_temperature_celsius"),
 			"Network device transceiver temperature (celsius)", []string{"device"}, nil),
 		interfaceTransceiverVoltage: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "transceiver_voltage"),
@@ -82,94 +106,119 @@ func NewInterfaceCollector(logger *slog.Logger) *interfaceCollector {
 			"Number of receive errs on an interface", []string{"device", "type"}, nil),
 		interfaceReceivedBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "receive_bytes_total"),
 			"Number of bytes received on an interface", []string{"device"}, nil),
-		scrapeDuration: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "scrape_duration_seconds"),
-			"Time it took for prometheus to scrape sonic interface metrics", nil, nil),
-		scrapeCollectorSuccess: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "collector_success"),
-			"Whether interface collector succeeded", nil, nil),
-		logger: logger,
+		interfacePfcRxPauseDuration: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "pfc_rx_pause_duration_seconds_total"),
+			"Time an interface priority was paused by received PFC frames", []string{"device", "priority"}, nil),
+		interfacePfcRxPackets: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "pfc_rx_packets_total"),
+			"Number of PFC frames received on an interface priority", []string{"device", "priority"}, nil),
+		interfacePfcTxPackets: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "pfc_tx_packets_total"),
+			"Number of PFC frames transmitted on an interface priority", []string{"device", "priority"}, nil),
+		interfaceFecCorrectedBits: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "fec_corrected_bit_errors_total"),
+			"Number of bit errors corrected by FEC on an interface", []string{"device"}, nil),
+		interfaceFecUncorrectableFrames: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "fec_uncorrectable_frames_total"),
+			"Number of received frames FEC failed to correct on an interface", []string{"device"}, nil),
+		interfaceBreakoutInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "breakout_info"),
+			"Breakout group of an interface, value is always 1", []string{"device", "breakout_group", "breakout_mode"}, nil),
+		counterSeries:     make(map[counterSeriesKey]counterSeries),
+		nextCounterSeries: make(map[counterSeriesKey]counterSeries),
+		redisClient:       redisClient,
 	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "interface", collector.scrapeMetrics)
+	collector.scrapeCache.commit = collector.commitCounterSeries
+
+	return collector
 }
 
-func (collector *interfaceCollector) Collect(ch chan<- prometheus.Metric) {
-	const cacheDuration = 15 * time.Second
+func (collector *interfaceCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	collector.nextCounterSeries = make(map[counterSeriesKey]counterSeries)
 
-	scrapeSuccess := 1.0
+	redisClient := collector.redisClient
 
-	var ctx = context.Background()
+	var metrics []prometheus.Metric
 
-	collector.mu.Lock()
-	defer collector.mu.Unlock()
+	ports, err := redisClient.HgetAllFromDb(ctx, "COUNTERS_DB", "COUNTERS_PORT_NAME_MAP")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
 
-	if time.Since(collector.lastScrapeTime) < cacheDuration {
-		// Return cached metrics without making redis calls
-		collector.logger.InfoContext(ctx, "Returning interface metrics from cache")
+	// Breakout children may not have counters yet, they are enumerated from CONFIG_DB as well
+	configPortKeys, err := redisClient.KeysFromDb(ctx, "CONFIG_DB", "PORT|*")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
 
-		for _, metric := range collector.cachedMetrics {
-			ch <- metric
+	// Interfaces of the counters name map come first, they are the ones with counters
+	var interfaceNames, counterKeys, configKeys, portKeys []string
+	for port := range ports {
+		if !collector.config.includeInterface(port) {
+			continue
 		}
-		return
+		interfaceNames = append(interfaceNames, port)
+		counterKeys = append(counterKeys, fmt.Sprintf("COUNTERS:%s", ports[port]))
 	}
 
-	err := collector.scrapeMetrics(ctx)
-	if err != nil {
-		scrapeSuccess = 0
-		collector.logger.ErrorContext(ctx, err.Error())
+	for _, configPortKey := range configPortKeys {
+		port := strings.TrimPrefix(configPortKey, "PORT|")
+		if _, ok := ports[port]; ok || !collector.config.includeInterface(port) {
+			continue
+		}
+		interfaceNames = append(interfaceNames, port)
 	}
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-		collector.scrapeCollectorSuccess, prometheus.GaugeValue, scrapeSuccess,
-	))
 
-	for _, cachedMetric := range collector.cachedMetrics {
-		ch <- cachedMetric
+	for _, interfaceName := range interfaceNames {
+		configKeys = append(configKeys, interfaceConfigKey(interfaceName))
+		portKeys = append(portKeys, fmt.Sprintf("PORT_TABLE:%s", interfaceName))
 	}
-}
-
-func (collector *interfaceCollector) scrapeMetrics(ctx context.Context) error {
-	collector.logger.InfoContext(ctx, "Starting interface metric scrape")
-	scrapeTime := time.Now()
 
-	redisClient, err := redis.NewClient()
+	counters, err := redisClient.HgetAllPipelined(ctx, "COUNTERS_DB", counterKeys)
 	if err != nil {
-		return fmt.Errorf("redis client initialization failed: %w", err)
+		return nil, fmt.Errorf("redis read failed: %w", err)
 	}
 
-	defer redisClient.Close()
-
-	// Reset metrics
-	collector.cachedMetrics = []prometheus.Metric{}
-
-	ports, err := redisClient.HgetAllFromDb(ctx, "COUNTERS_DB", "COUNTERS_PORT_NAME_MAP")
+	configInfo, err := redisClient.HgetAllPipelined(ctx, "CONFIG_DB", configKeys)
 	if err != nil {
-		return fmt.Errorf("redis read failed: %w", err)
+		return nil, fmt.Errorf("redis read failed: %w", err)
 	}
 
-	for port := range ports {
-		counterKey := fmt.Sprintf("COUNTERS:%s", ports[port])
+	portInfo, err := redisClient.HgetAllPipelined(ctx, "APPL_DB", portKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
 
-		err := collector.collectInterfaceCounters(ctx, redisClient, port, counterKey)
-		if err != nil {
-			return fmt.Errorf("interface counters collection failed: %w", err)
+	for i, interfaceName := range interfaceNames {
+		if i < len(counterKeys) {
+			interfaceCountersMetrics, err := collector.collectInterfaceCounters(interfaceName, counters[counterKeys[i]])
+			if err != nil {
+				return nil, fmt.Errorf("interface counters collection failed: %w", err)
+			}
+			metrics = append(metrics, interfaceCountersMetrics...)
 		}
 
-		err = collector.collectInterfaceInfo(ctx, redisClient, port)
+		interfaceInfoMetrics, err := collector.collectInterfaceInfo(interfaceName, configInfo[configKeys[i]], portInfo[portKeys[i]])
 		if err != nil {
-			return fmt.Errorf("interface info collection failed: %w", err)
+			return nil, fmt.Errorf("interface info collection failed: %w", err)
 		}
+		metrics = append(metrics, interfaceInfoMetrics...)
+	}
 
+	interfaceBreakoutInfoMetrics, err := collector.collectInterfaceBreakoutInfo(ctx, redisClient, configPortKeys)
+	if err != nil {
+		return nil, fmt.Errorf("interface breakout info collection failed: %w", err)
 	}
+	metrics = append(metrics, interfaceBreakoutInfoMetrics...)
 
-	err = collector.collectInterfaceOpticalInfo(ctx, redisClient)
+	interfaceOpticalInfoMetrics, err := collector.collectInterfaceOpticalInfo(ctx, redisClient)
 	if err != nil {
-		return fmt.Errorf("interface optical info collection failed: %w", err)
+		return nil, fmt.Errorf("interface optical info collection failed: %w", err)
 	}
+	metrics = append(metrics, interfaceOpticalInfoMetrics...)
 
-	collector.logger.InfoContext(ctx, "Ending interface metric scrape")
+	return metrics, nil
+}
 
-	collector.lastScrapeTime = time.Now()
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-		collector.scrapeDuration, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(),
-	))
-	return nil
+// commitCounterSeries keeps the counter series of a cached scrape for the next one
+func (collector *interfaceCollector) commitCounterSeries() {
+	collector.counterSeries = collector.nextCounterSeries
+	collector.nextCounterSeries = make(map[counterSeriesKey]counterSeries)
 }
 
 func (collector *interfaceCollector) Describe(ch chan<- *prometheus.Desc) {
@@ -178,6 +227,8 @@ func (collector *interfaceCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- collector.interfaceSpeed
 	ch <- collector.interfaceAdminStatus
 	ch <- collector.interfaceOperationslStatus
+	ch <- collector.interfaceOperStatusChanges
+	ch <- collector.interfaceLastOperChange
 	ch <- collector.interfaceTransceiverTemperature
 	ch <- collector.interfaceTransceiverVoltage
 	ch <- collector.interfaceOpticTransmitPower
@@ -190,111 +241,141 @@ func (collector *interfaceCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- collector.interfaceReceivePackets
 	ch <- collector.interfaceReceiveErrs
 	ch <- collector.interfaceReceivedBytes
-	ch <- collector.scrapeDuration
-	ch <- collector.scrapeCollectorSuccess
+	ch <- collector.interfacePfcRxPauseDuration
+	ch <- collector.interfacePfcRxPackets
+	ch <- collector.interfacePfcTxPackets
+	ch <- collector.interfaceFecCorrectedBits
+	ch <- collector.interfaceFecUncorrectableFrames
+	ch <- collector.interfaceBreakoutInfo
+	collector.describeScrape(ch)
 }
 
-func (collector *interfaceCollector) collectInterfaceCounters(ctx context.Context, redisClient redis.Client, interfaceName, counterKey string) error {
-	var counters map[string]string
+// counterMetric returns a counter metric whose created timestamp is the time the
+// series was first seen or the last time a counter reset was detected. The
+// series is tracked for the next scrape in nextCounterSeries.
+func (collector *interfaceCollector) counterMetric(desc *prometheus.Desc, value float64, labelValues ...string) prometheus.Metric {
+	key := counterSeriesKey{desc: desc, labels: strings.Join(labelValues, "\xff")}
+
+	series, ok := collector.counterSeries[key]
+	if !ok || value < series.value {
+		series = counterSeries{created: time.Now()}
+	}
+	series.value = value
+	collector.nextCounterSeries[key] = series
+
+	return prometheus.MustNewConstMetricWithCreatedTimestamp(desc, prometheus.CounterValue, value, series.created, labelValues...)
+}
 
-	// Retrieve packet counters from redis database
-	counters, err := redisClient.HgetAllFromDb(ctx, "COUNTERS_DB", counterKey)
+func (collector *interfaceCollector) collectInterfaceCounters(interfaceName string, counters map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	interfaceByteCountersMetrics, err := collector.collectInterfaceByteCounters(interfaceName, counters)
 	if err != nil {
-		return fmt.Errorf("redis read failed: %w", err)
+		return nil, fmt.Errorf("byte counters collection failed: %w", err)
 	}
+	metrics = append(metrics, interfaceByteCountersMetrics...)
 
-	err = collector.collectInterfaceByteCounters(interfaceName, counters)
+	interfaceErrCountersMetrics, err := collector.collectInterfaceErrCounters(interfaceName, counters)
 	if err != nil {
-		return fmt.Errorf("byte counters collection failed: %w", err)
+		return nil, fmt.Errorf("err counters collection failed: %w", err)
 	}
+	metrics = append(metrics, interfaceErrCountersMetrics...)
 
-	err = collector.collectInterfaceErrCounters(interfaceName, counters)
+	interfacePacketCountersMetrics, err := collector.collectInterfacePacketCounters(interfaceName, counters)
 	if err != nil {
-		return fmt.Errorf("err counters collection failed: %w", err)
+		return nil, fmt.Errorf("packet counters collection failed: %w", err)
 	}
+	metrics = append(metrics, interfacePacketCountersMetrics...)
 
-	err = collector.collectInterfacePacketCounters(interfaceName, counters)
+	interfacePacketSizeCountersMetrics, err := collector.collectInterfacePacketSizeCounters(interfaceName, counters)
 	if err != nil {
-		return fmt.Errorf("packet counters collection failed: %w", err)
+		return nil, fmt.Errorf("packet size counters collection failed: %w", err)
 	}
+	metrics = append(metrics, interfacePacketSizeCountersMetrics...)
 
-	err = collector.collectInterfacePacketSizeCounters(interfaceName, counters)
+	interfacePfcCountersMetrics, err := collector.collectInterfacePfcCounters(interfaceName, counters)
 	if err != nil {
-		return fmt.Errorf("packet size counters collection failed: %w", err)
+		return nil, fmt.Errorf("pfc counters collection failed: %w", err)
 	}
+	metrics = append(metrics, interfacePfcCountersMetrics...)
+
+	metrics = append(metrics, collector.collectInterfaceFecCounters(interfaceName, counters)...)
 
-	return nil
+	return metrics, nil
 
 }
 
-func (collector *interfaceCollector) collectInterfaceInfo(ctx context.Context, redisClient redis.Client, interfaceName string) error {
-	err := collector.collectInterfaceConfigInfo(ctx, redisClient, interfaceName)
-	if err != nil {
-		return err
-	}
+func (collector *interfaceCollector) collectInterfaceInfo(interfaceName string, configInfo, portInfo map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
 
-	err = collector.collectInterfaceOperationInfo(ctx, redisClient, interfaceName)
+	interfaceConfigInfoMetrics, err := collector.collectInterfaceConfigInfo(interfaceName, configInfo, portInfo)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	metrics = append(metrics, interfaceConfigInfoMetrics...)
 
-	return nil
-}
+	metrics = append(metrics, collector.collectInterfaceOperationInfo(interfaceName, portInfo)...)
 
-func (collector *interfaceCollector) collectInterfaceConfigInfo(ctx context.Context, redisClient redis.Client, interfaceName string) error {
-	var interfaceKey string = fmt.Sprintf("PORTCHANNEL|%s", interfaceName)
+	return metrics, nil
+}
 
+// interfaceConfigKey returns the CONFIG_DB key of an interface, ports are
+// configured in PORT and all other interfaces in PORTCHANNEL.
+func interfaceConfigKey(interfaceName string) string {
 	if strings.HasPrefix(interfaceName, "Ethernet") {
-		interfaceKey = fmt.Sprintf("PORT|%s", interfaceName)
+		return fmt.Sprintf("PORT|%s", interfaceName)
 	}
 
-	info, err := redisClient.HgetAllFromDb(ctx, "CONFIG_DB", interfaceKey)
-	if err != nil {
-		return fmt.Errorf("redis read failed: %w", err)
-	}
+	return fmt.Sprintf("PORTCHANNEL|%s", interfaceName)
+}
 
-	description, ok := info["description"]
-	if !ok {
-		description = ""
+// collectInterfaceConfigInfo reads the alias, index, MTU and speed of an
+// interface from its CONFIG_DB entry and the description from its PORT_TABLE
+// entry of APPL_DB, interfaces without PORT_TABLE entry have no description.
+func (collector *interfaceCollector) collectInterfaceConfigInfo(interfaceName string, info, portInfo map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	description := portInfo["description"]
+
+	// PortChannels and ports without alias are known by their name
+	alias := info["alias"]
+	if alias == "" {
+		alias = interfaceName
 	}
 
 	mtu, err := parseFloat(info["mtu"])
 	if err != nil {
-		return fmt.Errorf("value parse failed: %w", err)
+		return nil, fmt.Errorf("value parse failed: %w", err)
 	}
 
 	speed, err := parseFloat(info["speed"])
 	if err != nil {
-		return fmt.Errorf("value parse failed: %w", err)
+		return nil, fmt.Errorf("value parse failed: %w", err)
 	}
 
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-		collector.interfaceInfo, prometheus.GaugeValue, 1, interfaceName, info["alias"], info["index"], description,
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.interfaceInfo, prometheus.GaugeValue, 1, interfaceName, alias, info["index"], description,
 	))
 
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+	metrics = append(metrics, prometheus.MustNewConstMetric(
 		collector.interfaceMtu, prometheus.GaugeValue, mtu, interfaceName,
 	))
 
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-		collector.interfaceSpeed, prometheus.GaugeValue, speed*1000*1000/8, interfaceName,
+	metrics = append(metrics, derivedGauge(
+		collector.interfaceSpeed, speed*1000*1000/8, collector.config.Precision, interfaceName,
 	))
 
-	return nil
+	return metrics, nil
 }
 
-func (collector *interfaceCollector) collectInterfaceOperationInfo(ctx context.Context, redisClient redis.Client, interfaceName string) error {
+func (collector *interfaceCollector) collectInterfaceOperationInfo(interfaceName string, info map[string]string) []prometheus.Metric {
+	var metrics []prometheus.Metric
+
 	var (
-		portKey           string  = fmt.Sprintf("PORT_TABLE:%s", interfaceName)
 		adminStatus       float64 = 0
 		operationalStatus float64 = 0
 	)
 
-	info, err := redisClient.HgetAllFromDb(ctx, "APPL_DB", portKey)
-	if err != nil {
-		return fmt.Errorf("redis read failed: %w", err)
-	}
-
 	if info["admin_status"] == "up" {
 		adminStatus = 1
 	}
@@ -303,18 +384,49 @@ func (collector *interfaceCollector) collectInterfaceOperationInfo(ctx context.C
 		operationalStatus = 1
 	}
 
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+	metrics = append(metrics, prometheus.MustNewConstMetric(
 		collector.interfaceAdminStatus, prometheus.GaugeValue, adminStatus, interfaceName,
 	))
 
-	collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+	metrics = append(metrics, prometheus.MustNewConstMetric(
 		collector.interfaceOperationslStatus, prometheus.GaugeValue, operationalStatus, interfaceName,
 	))
 
-	return nil
+	// Only recorded by SONiC releases tracking link flaps
+	if flapCount, err := parseFloat(info["flap_count"]); err == nil && info["flap_count"] != "" {
+		metrics = append(metrics, collector.counterMetric(collector.interfaceOperStatusChanges, flapCount, interfaceName))
+	}
+
+	var lastChange time.Time
+	for _, field := range []string{"last_up_time", "last_down_time"} {
+		if changeTime, ok := parseOperChangeTime(info[field]); ok && changeTime.After(lastChange) {
+			lastChange = changeTime
+		}
+	}
+	if !lastChange.IsZero() {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.interfaceLastOperChange, prometheus.GaugeValue, float64(lastChange.Unix()), interfaceName,
+		))
+	}
+
+	return metrics
+}
+
+// parseOperChangeTime parses the time of an operational status change as
+// recorded by orchagent in last_up_time and last_down_time, e.g.
+// "Thu Oct 12 09:14:03 2023" in UTC. Missing or malformed times return false.
+func parseOperChangeTime(value string) (time.Time, bool) {
+	changeTime, err := time.Parse("Mon Jan 02 15:04:05 2006", strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return changeTime, true
 }
 
-func (collector *interfaceCollector) collectInterfaceOpticalInfo(ctx context.Context, redisClient redis.Client) error {
+func (collector *interfaceCollector) collectInterfaceOpticalInfo(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
 	const transceiverKeyPattern string = "TRANSCEIVER_DOM_SENSOR|*"
 	var (
 		rxPowerRegex = regexp.MustCompile(`^rx(\d*)power$`)
@@ -323,16 +435,23 @@ func (collector *interfaceCollector) collectInterfaceOpticalInfo(ctx context.Con
 
 	transceiverKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", transceiverKeyPattern)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var includedKeys []string
 	for _, transceiverKey := range transceiverKeys {
-		interfaceName := strings.Split(transceiverKey, "|")[1]
-
-		data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", transceiverKey)
-		if err != nil {
-			return err
+		if collector.config.includeInterface(strings.Split(transceiverKey, "|")[1]) {
+			includedKeys = append(includedKeys, transceiverKey)
 		}
+	}
+
+	transceiverData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", includedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	for transceiverKey, data := range transceiverData {
+		interfaceName := strings.Split(transceiverKey, "|")[1]
 
 		for metric, value := range data {
 			parsedValue, err := parseFloat(value)
@@ -342,58 +461,62 @@ func (collector *interfaceCollector) collectInterfaceOpticalInfo(ctx context.Con
 
 			switch name := metric; {
 			case name == "temperature":
-				collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+				metrics = append(metrics, prometheus.MustNewConstMetric(
 					collector.interfaceTransceiverTemperature, prometheus.GaugeValue, parsedValue, interfaceName,
 				))
 			case name == "voltage":
-				collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+				metrics = append(metrics, prometheus.MustNewConstMetric(
 					collector.interfaceTransceiverVoltage, prometheus.GaugeValue, parsedValue, interfaceName,
 				))
 			case rxPowerRegex.MatchString(name):
 				opticUnit := rxPowerRegex.FindStringSubmatch(name)[1]
-				collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+				metrics = append(metrics, prometheus.MustNewConstMetric(
 					collector.interfaceOpticReceivePower, prometheus.GaugeValue, parsedValue, interfaceName, opticUnit,
 				))
 			case txPowerRegex.MatchString(name):
 				opticUnit := txPowerRegex.FindStringSubmatch(name)[1]
-				collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
+				metrics = append(metrics, prometheus.MustNewConstMetric(
 					collector.interfaceOpticTransmitPower, prometheus.GaugeValue, parsedValue, interfaceName, opticUnit,
 				))
 			}
 		}
 	}
-	return nil
+	return metrics, nil
 }
 
-func (collector *interfaceCollector) collectInterfaceByteCounters(interfaceName string, counters map[string]string) error {
+func (collector *interfaceCollector) collectInterfaceByteCounters(interfaceName string, counters map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
 	const interfaceByteCountKey = "SAI_PORT_STAT_IF_%s_OCTETS"
 
 	for _, direction := range []string{"in", "out"} {
 		bytes, err := parseFloat(counters[fmt.Sprintf(interfaceByteCountKey, strings.ToUpper(direction))])
 		if err != nil {
-			return fmt.Errorf("value parse failed: %w", err)
+			return nil, fmt.Errorf("value parse failed: %w", err)
 		}
 
 		switch direction {
 		case "in":
-			collector.cachedMetrics = append(collector.cachedMetrics,
-				prometheus.MustNewConstMetric(
-					collector.interfaceReceivedBytes, prometheus.CounterValue, bytes, interfaceName,
+			metrics = append(metrics,
+				collector.counterMetric(
+					collector.interfaceReceivedBytes, bytes, interfaceName,
 				),
 			)
 		case "out":
-			collector.cachedMetrics = append(collector.cachedMetrics,
-				prometheus.MustNewConstMetric(
-					collector.interfaceTransmitBytes, prometheus.CounterValue, bytes, interfaceName,
+			metrics = append(metrics,
+				collector.counterMetric(
+					collector.interfaceTransmitBytes, bytes, interfaceName,
 				),
 			)
 		}
 	}
 
-	return nil
+	return metrics, nil
 }
 
-func (collector *interfaceCollector) collectInterfaceErrCounters(interfaceName string, counters map[string]string) error {
+func (collector *interfaceCollector) collectInterfaceErrCounters(interfaceName string, counters map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
 	var interfaceErrorTypeMap = map[string]map[string]string{
 		"in": {
 			"error":   "SAI_PORT_STAT_IF_IN_ERRORS",
@@ -412,57 +535,59 @@ func (collector *interfaceCollector) collectInterfaceErrCounters(interfaceName s
 		for errType, key := range interfaceErrorTypeMap[direction] {
 			packets, err := parseFloat(counters[key])
 			if err != nil {
-				return fmt.Errorf("value parse failed: %w", err)
+				return nil, fmt.Errorf("value parse failed: %w", err)
 			}
 
 			switch direction {
 			case "in":
-				collector.cachedMetrics = append(collector.cachedMetrics,
-					prometheus.MustNewConstMetric(
-						collector.interfaceReceiveErrs, prometheus.CounterValue, packets, interfaceName, errType,
+				metrics = append(metrics,
+					collector.counterMetric(
+						collector.interfaceReceiveErrs, packets, interfaceName, errType,
 					),
 				)
 			case "out":
-				collector.cachedMetrics = append(collector.cachedMetrics,
-					prometheus.MustNewConstMetric(
-						collector.interfaceTransmitErrs, prometheus.CounterValue, packets, interfaceName, errType,
+				metrics = append(metrics,
+					collector.counterMetric(
+						collector.interfaceTransmitErrs, packets, interfaceName, errType,
 					),
 				)
 			}
 		}
 	}
 
-	return nil
+	return metrics, nil
 }
 
-func (collector *interfaceCollector) collectInterfacePacketCounters(interfaceName string, counters map[string]string) error {
+func (collector *interfaceCollector) collectInterfacePacketCounters(interfaceName string, counters map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
 	const interfacePacketCountKey = "SAI_PORT_STAT_IF_%s_%s_PKTS"
 
 	for _, direction := range []string{"in", "out"} {
 		for _, method := range []string{"ucast", "broadcast", "multicast"} {
 			packets, err := parseFloat(counters[fmt.Sprintf(interfacePacketCountKey, strings.ToUpper(direction), strings.ToUpper(method))])
 			if err != nil {
-				return fmt.Errorf("value parse failed: %w", err)
+				return nil, fmt.Errorf("value parse failed: %w", err)
 			}
 
 			switch direction {
 			case "in":
-				collector.cachedMetrics = append(collector.cachedMetrics,
-					prometheus.MustNewConstMetric(
-						collector.interfaceReceivePackets, prometheus.CounterValue, packets, interfaceName, method,
+				metrics = append(metrics,
+					collector.counterMetric(
+						collector.interfaceReceivePackets, packets, interfaceName, method,
 					),
 				)
 			case "out":
-				collector.cachedMetrics = append(collector.cachedMetrics,
-					prometheus.MustNewConstMetric(
-						collector.interfaceTransmitPackets, prometheus.CounterValue, packets, interfaceName, method,
+				metrics = append(metrics,
+					collector.counterMetric(
+						collector.interfaceTransmitPackets, packets, interfaceName, method,
 					),
 				)
 			}
 		}
 	}
 
-	return nil
+	return metrics, nil
 }
 
 func (p packetSize) format(direction string) string {
@@ -494,28 +619,161 @@ func (p packetSize) format(direction string) string {
 	return ""
 }
 
-func (collector *interfaceCollector) collectInterfacePacketSizeCounters(interfaceName string, counters map[string]string) error {
+func (collector *interfaceCollector) collectInterfacePacketSizeCounters(interfaceName string, counters map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
 	var sizes = []packetSize{"64", "127", "255", "511", "1023", "1518", "2047", "4095", "9216", "16383"}
 
 	for _, direction := range []string{"in", "out"} {
 		for _, size := range sizes {
 			bytes, err := parseFloat(counters[size.format(direction)])
 			if err != nil {
-				return fmt.Errorf("value parse failed: %w", err)
+				return nil, fmt.Errorf("value parse failed: %w", err)
 			}
 
 			switch direction {
 			case "in":
-				collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-					collector.interfaceReceiveEthernetPackets, prometheus.CounterValue, bytes, interfaceName, string(size),
+				metrics = append(metrics, collector.counterMetric(
+					collector.interfaceReceiveEthernetPackets, bytes, interfaceName, string(size),
 				))
 			case "out":
-				collector.cachedMetrics = append(collector.cachedMetrics, prometheus.MustNewConstMetric(
-					collector.interfaceTransmitEthernetPackets, prometheus.CounterValue, bytes, interfaceName, string(size),
+				metrics = append(metrics, collector.counterMetric(
+					collector.interfaceTransmitEthernetPackets, bytes, interfaceName, string(size),
 				))
 			}
 		}
 	}
 
-	return nil
+	return metrics, nil
+}
+
+// collectInterfacePfcCounters reads the received and transmitted PFC frames and
+// the accumulated PFC pause duration per priority. SAI reports the duration in
+// microseconds, either as PFC_<n>_RX_PAUSE_DURATION_US or PFC_<n>_RX_PAUSE_DURATION
+// depending on the platform. Counters missing for a priority are skipped.
+func (collector *interfaceCollector) collectInterfacePfcCounters(interfaceName string, counters map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	pfcPacketCounters := map[*prometheus.Desc]string{
+		collector.interfacePfcRxPackets: "SAI_PORT_STAT_PFC_%d_RX_PKTS",
+		collector.interfacePfcTxPackets: "SAI_PORT_STAT_PFC_%d_TX_PKTS",
+	}
+
+	for priority := 0; priority < 8; priority++ {
+		for desc, field := range pfcPacketCounters {
+			value, ok := counters[fmt.Sprintf(field, priority)]
+			if !ok {
+				continue
+			}
+
+			packets, err := parseFloat(value)
+			if err != nil {
+				return nil, fmt.Errorf("value parse failed: %w", err)
+			}
+
+			metrics = append(metrics, collector.counterMetric(desc, packets, interfaceName, strconv.Itoa(priority)))
+		}
+
+		duration, ok := counters[fmt.Sprintf("SAI_PORT_STAT_PFC_%d_RX_PAUSE_DURATION_US", priority)]
+		if !ok {
+			duration, ok = counters[fmt.Sprintf("SAI_PORT_STAT_PFC_%d_RX_PAUSE_DURATION", priority)]
+		}
+		if !ok {
+			continue
+		}
+
+		microseconds, err := parseFloat(duration)
+		if err != nil {
+			return nil, fmt.Errorf("value parse failed: %w", err)
+		}
+
+		metrics = append(metrics,
+			collector.counterMetric(
+				collector.interfacePfcRxPauseDuration, microseconds/1e6, interfaceName, strconv.Itoa(priority),
+			),
+		)
+	}
+
+	return metrics, nil
+}
+
+// collectInterfaceFecCounters reads the FEC counters, which are only present on
+// platforms and ports supporting FEC. Counters that cannot be parsed are skipped.
+func (collector *interfaceCollector) collectInterfaceFecCounters(interfaceName string, counters map[string]string) []prometheus.Metric {
+	var metrics []prometheus.Metric
+
+	fecCounters := map[*prometheus.Desc]string{
+		collector.interfaceFecCorrectedBits:       "SAI_PORT_STAT_IF_IN_FEC_CORRECTED_BITS",
+		collector.interfaceFecUncorrectableFrames: "SAI_PORT_STAT_IF_IN_FEC_NOT_CORRECTABLE_FRAMES",
+	}
+
+	for desc, field := range fecCounters {
+		value, ok := counters[field]
+		if !ok {
+			continue
+		}
+
+		parsedValue, err := parseFloat(value)
+		if err != nil {
+			continue
+		}
+
+		metrics = append(metrics, collector.counterMetric(desc, parsedValue, interfaceName))
+	}
+
+	return metrics
+}
+
+// collectInterfaceBreakoutInfo ties breakout children to their physical cage.
+// BREAKOUT_CFG is keyed by the parent port of a cage and all children share the
+// front panel index of their parent.
+func (collector *interfaceCollector) collectInterfaceBreakoutInfo(ctx context.Context, redisClient *redis.Client, configPortKeys []string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const breakoutKeyPattern string = "BREAKOUT_CFG|*"
+
+	breakoutKeys, err := redisClient.KeysFromDb(ctx, "CONFIG_DB", breakoutKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	if len(breakoutKeys) == 0 {
+		return metrics, nil
+	}
+
+	portInfo, err := redisClient.HgetAllPipelined(ctx, "CONFIG_DB", configPortKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	portIndexes := make(map[string]string)
+	for configPortKey, info := range portInfo {
+		portIndexes[strings.TrimPrefix(configPortKey, "PORT|")] = info["index"]
+	}
+
+	breakoutData, err := redisClient.HgetAllPipelined(ctx, "CONFIG_DB", breakoutKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for breakoutKey, breakout := range breakoutData {
+		breakoutGroup := strings.TrimPrefix(breakoutKey, "BREAKOUT_CFG|")
+
+		groupIndex, ok := portIndexes[breakoutGroup]
+		if !ok || groupIndex == "" {
+			continue
+		}
+
+		for port, index := range portIndexes {
+			if index != groupIndex || !collector.config.includeInterface(port) {
+				continue
+			}
+
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.interfaceBreakoutInfo, prometheus.GaugeValue, 1, port, breakoutGroup, breakout["brkout_mode"],
+			))
+		}
+	}
+
+	return metrics, nil
 }
@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type mclagCollector struct {
+	*scrapeCache
+	mclagSessionStatus   *prometheus.Desc
+	mclagPeerLinkStatus  *prometheus.Desc
+	mclagKeepaliveStatus *prometheus.Desc
+	redisClient          *redis.Client
+}
+
+func NewMclagCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *mclagCollector {
+	const subsystem = "mclag"
+	namespace := config.namespace()
+
+	collector := &mclagCollector{
+		mclagSessionStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "session_status"),
+			"MCLAG ICCP session status with the peer: 0(DOWN), 1(UP)", []string{"domain"}, nil),
+		mclagPeerLinkStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "peer_link_status"),
+			"MCLAG peer link operational status: 0(DOWN), 1(UP)", []string{"domain"}, nil),
+		mclagKeepaliveStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "keepalive_status"),
+			"MCLAG keepalive status with the peer: 0(ERROR), 1(OK)", []string{"domain"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "mclag", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *mclagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.mclagSessionStatus
+	ch <- collector.mclagPeerLinkStatus
+	ch <- collector.mclagKeepaliveStatus
+	collector.describeScrape(ch)
+}
+
+func (collector *mclagCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	mclagDomainsMetrics, err := collector.collectMclagDomains(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("mclag domain collection failed: %w", err)
+	}
+	metrics = append(metrics, mclagDomainsMetrics...)
+
+	return metrics, nil
+}
+
+// collectMclagDomains reads the MCLAG domains configured in MCLAG_DOMAIN and
+// their ICCP session and keepalive state from MCLAG_TABLE in STATE_DB, as
+// shown by mclagdctl. Domains without state report the session and keepalive
+// as down. The peer link status is the operational status of the PortChannel
+// or port configured as peer_link, domains without one don't report it.
+// Devices not configured for MCLAG report no series.
+func (collector *mclagCollector) collectMclagDomains(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const domainKeyPattern string = "MCLAG_DOMAIN|*"
+
+	domainKeys, err := redisClient.KeysFromDb(ctx, "CONFIG_DB", domainKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	domainConfigs, err := redisClient.HgetAllPipelined(ctx, "CONFIG_DB", domainKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	var stateKeys []string
+	for _, domainKey := range domainKeys {
+		stateKeys = append(stateKeys, fmt.Sprintf("MCLAG_TABLE|%s", strings.TrimPrefix(domainKey, "MCLAG_DOMAIN|")))
+	}
+
+	states, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", stateKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	// peer links are read once the peer link of every domain is known
+	var peerLinkDomains, peerLinkKeys []string
+
+	for i, domainKey := range domainKeys {
+		domainId := strings.TrimPrefix(domainKey, "MCLAG_DOMAIN|")
+		state := states[stateKeys[i]]
+
+		sessionStatus := 0.0
+		if strings.EqualFold(state["oper_status"], "up") {
+			sessionStatus = 1.0
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.mclagSessionStatus, prometheus.GaugeValue, sessionStatus, domainId,
+		))
+
+		keepaliveStatus := 0.0
+		if strings.EqualFold(state["keepalive"], "ok") {
+			keepaliveStatus = 1.0
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.mclagKeepaliveStatus, prometheus.GaugeValue, keepaliveStatus, domainId,
+		))
+
+		peerLink := domainConfigs[domainKey]["peer_link"]
+		if peerLink == "" {
+			continue
+		}
+
+		peerLinkKey := fmt.Sprintf("PORT_TABLE:%s", peerLink)
+		if strings.HasPrefix(peerLink, "PortChannel") {
+			peerLinkKey = fmt.Sprintf("LAG_TABLE:%s", peerLink)
+		}
+
+		peerLinkDomains = append(peerLinkDomains, domainId)
+		peerLinkKeys = append(peerLinkKeys, peerLinkKey)
+	}
+
+	peerLinks, err := redisClient.HgetAllPipelined(ctx, "APPL_DB", peerLinkKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for i, domainId := range peerLinkDomains {
+		peerLinkStatus := 0.0
+		if peerLinks[peerLinkKeys[i]]["oper_status"] == "up" {
+			peerLinkStatus = 1.0
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.mclagPeerLinkStatus, prometheus.GaugeValue, peerLinkStatus, domainId,
+		))
+	}
+
+	return metrics, nil
+}
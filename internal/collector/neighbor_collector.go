@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type neighborCollector struct {
+	*scrapeCache
+	neighborTotalEntries *prometheus.Desc
+	neighborEntries      *prometheus.Desc
+	redisClient          *redis.Client
+}
+
+func NewNeighborCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *neighborCollector {
+	const subsystem = "neighbor"
+	namespace := config.namespace()
+
+	collector := &neighborCollector{
+		neighborTotalEntries: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "total_entries"),
+			"Number of entries in the neighbor (ARP/NDP) table", nil, nil),
+		neighborEntries: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "entries"),
+			"Number of neighbor (ARP/NDP) table entries of an address family", []string{"family"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "neighbor", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *neighborCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.neighborTotalEntries
+	ch <- collector.neighborEntries
+	collector.describeScrape(ch)
+}
+
+func (collector *neighborCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	neighborEntriesMetrics, err := collector.collectNeighborEntries(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("neighbor collection failed: %w", err)
+	}
+	metrics = append(metrics, neighborEntriesMetrics...)
+
+	return metrics, nil
+}
+
+// collectNeighborEntries counts the NEIGH_TABLE:<interface>:<address> entries
+// by address family. Keys whose address cannot be parsed are skipped.
+func (collector *neighborCollector) collectNeighborEntries(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const neighborKeyPattern string = "NEIGH_TABLE:*"
+
+	neighborKeys, err := redisClient.KeysFromDb(ctx, "APPL_DB", neighborKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	familyEntries := map[string]int{"ipv4": 0, "ipv6": 0}
+	totalEntries := 0
+
+	for _, neighborKey := range neighborKeys {
+		// IPv6 addresses contain the separator, the address is everything after the interface
+		keyParts := strings.SplitN(neighborKey, ":", 3)
+		if len(keyParts) != 3 {
+			continue
+		}
+
+		address := net.ParseIP(keyParts[2])
+		if address == nil {
+			continue
+		}
+
+		family := "ipv6"
+		if address.To4() != nil {
+			family = "ipv4"
+		}
+
+		familyEntries[family]++
+		totalEntries++
+	}
+
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.neighborTotalEntries, prometheus.GaugeValue, float64(totalEntries),
+	))
+
+	for family, entries := range familyEntries {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.neighborEntries, prometheus.GaugeValue, float64(entries), family,
+		))
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ntpCollector struct {
+	*scrapeCache
+	ntpSynced        *prometheus.Desc
+	ntpOffsetSeconds *prometheus.Desc
+	redisClient      *redis.Client
+}
+
+func NewNtpCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *ntpCollector {
+	const subsystem = "ntp"
+	namespace := config.namespace()
+
+	collector := &ntpCollector{
+		ntpSynced: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "synced"),
+			"Whether the system clock is synchronized by NTP: 0(UNSYNCHRONIZED), 1(SYNCHRONIZED)", nil, nil),
+		ntpOffsetSeconds: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "offset_seconds"),
+			"Offset of the system clock to the selected NTP server", nil, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "ntp", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *ntpCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.ntpSynced
+	ch <- collector.ntpOffsetSeconds
+	collector.describeScrape(ch)
+}
+
+func (collector *ntpCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	ntpStatusMetrics, err := collector.collectNtpStatus(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("ntp status collection failed: %w", err)
+	}
+	metrics = append(metrics, ntpStatusMetrics...)
+
+	return metrics, nil
+}
+
+// collectNtpStatus reads the NTP synchronization state from NTP_STATUS|localhost.
+// The offset is reported in milliseconds, like ntpq and chronyc do, and is
+// only present while a server is selected. Systems without NTP status yield no
+// series.
+func (collector *ntpCollector) collectNtpStatus(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const ntpStatusKey string = "NTP_STATUS|localhost"
+
+	data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", ntpStatusKey)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	status, ok := data["status"]
+	if !ok {
+		return metrics, nil
+	}
+
+	synced := 0.0
+	switch strings.ToLower(status) {
+	case "synchronized", "synced", "true":
+		synced = 1.0
+	}
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.ntpSynced, prometheus.GaugeValue, synced,
+	))
+
+	offsetMilliseconds, ok, err := parseMeasurement(data["offset"])
+	if err != nil {
+		return nil, fmt.Errorf("value parse failed: %w", err)
+	}
+	if ok {
+		metrics = append(metrics, derivedGauge(
+			collector.ntpOffsetSeconds, offsetMilliseconds/1000, collector.config.Precision,
+		))
+	}
+
+	return metrics, nil
+}
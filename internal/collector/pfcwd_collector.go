@@ -0,0 +1,124 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type pfcWdCollector struct {
+	*scrapeCache
+	pfcWdStatus        *prometheus.Desc
+	pfcWdStormDetected *prometheus.Desc
+	pfcWdRestored      *prometheus.Desc
+	redisClient        *redis.Client
+}
+
+func NewPfcWdCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *pfcWdCollector {
+	const subsystem = "pfcwd"
+	namespace := config.namespace()
+
+	collector := &pfcWdCollector{
+		pfcWdStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "status"),
+			"PFC watchdog queue status: 0(OK), 1(STORMED)", []string{"device", "queue"}, nil),
+		pfcWdStormDetected: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "storm_detected_total"),
+			"Number of PFC storms detected on a queue", []string{"device", "queue"}, nil),
+		pfcWdRestored: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "restored_total"),
+			"Number of queues restored after a PFC storm", []string{"device", "queue"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "pfc watchdog", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *pfcWdCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.pfcWdStatus
+	ch <- collector.pfcWdStormDetected
+	ch <- collector.pfcWdRestored
+	collector.describeScrape(ch)
+}
+
+func (collector *pfcWdCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	pfcWdQueuesMetrics, err := collector.collectPfcWdQueues(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("pfc watchdog collection failed: %w", err)
+	}
+	metrics = append(metrics, pfcWdQueuesMetrics...)
+
+	return metrics, nil
+}
+
+// collectPfcWdQueues reads the watchdog state of the queues of every port
+// listed in PFC_WD_TABLE. Ports without PFC watchdog configured have no entry
+// and queues it does not monitor have no PFC_WD_STATUS, both yield no series.
+func (collector *pfcWdCollector) collectPfcWdQueues(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const pfcWdKeyPattern string = "PFC_WD_TABLE:Ethernet*"
+
+	pfcWdKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", pfcWdKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	if len(pfcWdKeys) == 0 {
+		return metrics, nil
+	}
+
+	pfcWdPorts := make(map[string]bool)
+	for _, pfcWdKey := range pfcWdKeys {
+		pfcWdPorts[strings.TrimPrefix(pfcWdKey, "PFC_WD_TABLE:")] = true
+	}
+
+	// only the queues of ports under watch are read
+	queues, err := resolveNameMap(ctx, redisClient, "COUNTERS_QUEUE_NAME_MAP", "COUNTERS", func(port, index string) bool {
+		return index != "" && pfcWdPorts[port]
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, queue := range queues {
+		portName, queueIndex, counters := queue.port, queue.index, queue.fields
+
+		status, ok := counters["PFC_WD_STATUS"]
+		if !ok {
+			continue
+		}
+
+		stormed := 0.0
+		if status == "stormed" {
+			stormed = 1.0
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.pfcWdStatus, prometheus.GaugeValue, stormed, portName, queueIndex,
+		))
+
+		detected, err := parseFloat(counters["PFC_WD_QUEUE_STATS_DEADLOCK_DETECTED"])
+		if err != nil {
+			return nil, fmt.Errorf("value parse failed: %w", err)
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.pfcWdStormDetected, prometheus.CounterValue, detected, portName, queueIndex,
+		))
+
+		restored, err := parseFloat(counters["PFC_WD_QUEUE_STATS_DEADLOCK_RESTORED"])
+		if err != nil {
+			return nil, fmt.Errorf("value parse failed: %w", err)
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.pfcWdRestored, prometheus.CounterValue, restored, portName, queueIndex,
+		))
+	}
+
+	return metrics, nil
+}
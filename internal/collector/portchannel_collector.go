@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type portChannelCollector struct {
+	*scrapeCache
+	portChannelOperStatus   *prometheus.Desc
+	portChannelMembers      *prometheus.Desc
+	portChannelMemberStatus *prometheus.Desc
+	redisClient             *redis.Client
+}
+
+func NewPortChannelCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *portChannelCollector {
+	const subsystem = "portchannel"
+	namespace := config.namespace()
+
+	collector := &portChannelCollector{
+		portChannelOperStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "oper_status"),
+			"PortChannel operational status: 0(DOWN), 1(UP)", []string{"device"}, nil),
+		portChannelMembers: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "members"),
+			"Number of members configured in a PortChannel", []string{"device"}, nil),
+		portChannelMemberStatus: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "member_status"),
+			"PortChannel member status: 0(UNSELECTED), 1(SELECTED)", []string{"device", "member"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "portchannel", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *portChannelCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.portChannelOperStatus
+	ch <- collector.portChannelMembers
+	ch <- collector.portChannelMemberStatus
+	collector.describeScrape(ch)
+}
+
+func (collector *portChannelCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	portChannelsMetrics, err := collector.collectPortChannels(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("portchannel collection failed: %w", err)
+	}
+	metrics = append(metrics, portChannelsMetrics...)
+
+	return metrics, nil
+}
+
+// collectPortChannels reads the PortChannels from LAG_TABLE and their members
+// from LAG_MEMBER_TABLE:<portchannel>:<member> of APPL_DB. Whether LACP selected
+// a member is read from runner.aggregator.selected, which teamd publishes in
+// LAG_MEMBER_TABLE|<portchannel>|<member> of STATE_DB. Members teamd reports
+// nothing for are unselected, PortChannels without members report zero members.
+func (collector *portChannelCollector) collectPortChannels(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const (
+		lagKeyPattern       string = "LAG_TABLE:PortChannel*"
+		lagMemberKeyPattern string = "LAG_MEMBER_TABLE:*"
+	)
+
+	lagKeys, err := redisClient.KeysFromDb(ctx, "APPL_DB", lagKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	lagMembers := make(map[string]int)
+
+	lagData, err := redisClient.HgetAllPipelined(ctx, "APPL_DB", lagKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for lagKey, data := range lagData {
+		lagName := strings.TrimPrefix(lagKey, "LAG_TABLE:")
+		lagMembers[lagName] = 0
+
+		operStatus := 0.0
+		if data["oper_status"] == "up" {
+			operStatus = 1.0
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.portChannelOperStatus, prometheus.GaugeValue, operStatus, lagName,
+		))
+	}
+
+	lagMemberKeys, err := redisClient.KeysFromDb(ctx, "APPL_DB", lagMemberKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	var lagNames, memberNames, memberStateKeys []string
+	for _, lagMemberKey := range lagMemberKeys {
+		keyParts := strings.SplitN(lagMemberKey, ":", 3)
+		if len(keyParts) != 3 {
+			continue
+		}
+		lagName, memberName := keyParts[1], keyParts[2]
+
+		if _, ok := lagMembers[lagName]; !ok {
+			continue
+		}
+		lagMembers[lagName]++
+
+		lagNames = append(lagNames, lagName)
+		memberNames = append(memberNames, memberName)
+		memberStateKeys = append(memberStateKeys, fmt.Sprintf("LAG_MEMBER_TABLE|%s|%s", lagName, memberName))
+	}
+
+	memberStates, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", memberStateKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for i, memberStateKey := range memberStateKeys {
+		lagName, memberName, data := lagNames[i], memberNames[i], memberStates[memberStateKey]
+
+		selected := 0.0
+		if data["runner.aggregator.selected"] == "true" {
+			selected = 1.0
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.portChannelMemberStatus, prometheus.GaugeValue, selected, lagName, memberName,
+		))
+	}
+
+	for lagName, members := range lagMembers {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.portChannelMembers, prometheus.GaugeValue, float64(members), lagName,
+		))
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,163 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// interpreterPattern matches the interpreters running SONiC daemons written in a
+// scripting language, like xcvrd or bgpcfgd in python3
+var interpreterPattern = regexp.MustCompile(`^(python[0-9.]*|perl|sh|bash)$`)
+
+type processCollector struct {
+	*scrapeCache
+	processCpuPercent    *prometheus.Desc
+	processMemoryPercent *prometheus.Desc
+	processMemoryBytes   *prometheus.Desc
+	redisClient          *redis.Client
+}
+
+func NewProcessCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *processCollector {
+	const subsystem = "process"
+	namespace := config.namespace()
+
+	collector := &processCollector{
+		processCpuPercent: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "cpu_percent"),
+			"CPU utilization of the processes of a name in percent", []string{"process"}, nil),
+		processMemoryPercent: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "memory_percent"),
+			"Memory utilization of the processes of a name in percent", []string{"process"}, nil),
+		processMemoryBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "memory_bytes"),
+			"Memory used by the processes of a name, derived from their share of the total memory", []string{"process"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "process", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *processCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.processCpuPercent
+	ch <- collector.processMemoryPercent
+	ch <- collector.processMemoryBytes
+	collector.describeScrape(ch)
+}
+
+func (collector *processCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	processStatsMetrics, err := collector.collectProcessStats(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("process stats collection failed: %w", err)
+	}
+	metrics = append(metrics, processStatsMetrics...)
+
+	return metrics, nil
+}
+
+// collectProcessStats reads the per-process resource usage published by
+// procdockerstatsd as PROCESS_STATS|<pid> and sums it up per process name, so
+// restarted daemons keep their series and workers of a daemon add up. Devices
+// not publishing it yield no series. Entries without a command or with an
+// unparsable CPU usage are skipped. procdockerstatsd publishes no resident
+// memory, memory in bytes is derived from %MEM and the total memory in
+// SYSTEM_STATS|MEMORY and left out if the total is not published.
+func (collector *processCollector) collectProcessStats(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const processKeyPattern string = "PROCESS_STATS|*"
+
+	processKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", processKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	memoryData, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "SYSTEM_STATS|MEMORY")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+	memoryTotal, memoryTotalOk, err := parseMeasurement(memoryData["total"])
+	if err != nil {
+		collector.logger.DebugContext(ctx, "Skipping malformed total memory", "err", err)
+	}
+
+	cpuPercents := make(map[string]float64)
+	memPercents := make(map[string]float64)
+
+	processData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", processKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for processKey, data := range processData {
+		pid := strings.Split(processKey, "|")[1]
+
+		command := strings.Fields(data["CMD"])
+		if len(command) == 0 {
+			collector.logger.DebugContext(ctx, "Skipping process without command", "pid", pid)
+			continue
+		}
+		processName := processName(command)
+
+		cpuPercent, err := strconv.ParseFloat(data["%CPU"], 64)
+		if err != nil {
+			collector.logger.DebugContext(ctx, "Skipping malformed process stats", "pid", pid, "err", err)
+			continue
+		}
+		cpuPercents[processName] += cpuPercent
+
+		// memory metrics are summed up only if values can be parsed
+		memPercent, err := strconv.ParseFloat(data["%MEM"], 64)
+		if err == nil {
+			memPercents[processName] += memPercent
+		}
+	}
+
+	memBytes := make(map[string]float64)
+	if memoryTotalOk {
+		for processName, memPercent := range memPercents {
+			memBytes[processName] = math.Round(memPercent / 100 * memoryTotal)
+		}
+	}
+
+	for desc, values := range map[*prometheus.Desc]map[string]float64{
+		collector.processCpuPercent:    cpuPercents,
+		collector.processMemoryPercent: memPercents,
+		collector.processMemoryBytes:   memBytes,
+	} {
+		for processName, value := range values {
+			metrics = append(metrics, derivedGauge(
+				desc, value, collector.config.Precision, processName,
+			))
+		}
+	}
+
+	return metrics, nil
+}
+
+// processName returns the name of the process running command. Processes of
+// interpreters are named after their script, the first argument not being a flag.
+func processName(command []string) string {
+	name := path.Base(command[0])
+	if !interpreterPattern.MatchString(name) {
+		return name
+	}
+
+	for _, arg := range command[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			return path.Base(arg)
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type queueCollector struct {
+	*scrapeCache
+	queuePackets        *prometheus.Desc
+	queueBytes          *prometheus.Desc
+	queueDroppedPackets *prometheus.Desc
+	queueWredEcnMarked  *prometheus.Desc
+	queueWredDropped    *prometheus.Desc
+	queueOccupancyBytes *prometheus.Desc
+	redisClient         *redis.Client
+}
+
+func NewQueueCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *queueCollector {
+	const subsystem = "queue"
+	namespace := config.namespace()
+
+	collector := &queueCollector{
+		queuePackets: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "packets_total"),
+			"Number of packets transmitted through a queue", []string{"device", "queue", "type"}, nil),
+		queueBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "bytes_total"),
+			"Number of bytes transmitted through a queue", []string{"device", "queue", "type"}, nil),
+		queueDroppedPackets: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "dropped_packets_total"),
+			"Number of packets dropped by a queue", []string{"device", "queue", "type"}, nil),
+		queueWredEcnMarked: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "wred_ecn_marked_packets_total"),
+			"Number of packets ECN marked by WRED on a queue", []string{"device", "queue"}, nil),
+		queueWredDropped: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "wred_dropped_packets_total"),
+			"Number of packets dropped by WRED on a queue per packet color", []string{"device", "queue", "color"}, nil),
+		queueOccupancyBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "occupancy_bytes"),
+			"Number of bytes currently buffered in a queue", []string{"device", "queue"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "queue", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.queuePackets
+	ch <- collector.queueBytes
+	ch <- collector.queueDroppedPackets
+	ch <- collector.queueWredEcnMarked
+	ch <- collector.queueWredDropped
+	ch <- collector.queueOccupancyBytes
+	collector.describeScrape(ch)
+}
+
+func (collector *queueCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	queueCountersMetrics, err := collector.collectQueueCounters(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("queue counters collection failed: %w", err)
+	}
+	metrics = append(metrics, queueCountersMetrics...)
+
+	return metrics, nil
+}
+
+// collectQueueCounters resolves the queues of each port through
+// COUNTERS_QUEUE_NAME_MAP ("Ethernet0:3" -> queue oid) and reads their
+// counters. Unicast and multicast queues share the index space of a port, the
+// queue type is taken from COUNTERS_QUEUE_TYPE_MAP. Counters that cannot be
+// parsed are skipped.
+func (collector *queueCollector) collectQueueCounters(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	queueCounters := map[*prometheus.Desc]string{
+		collector.queuePackets:        "SAI_QUEUE_STAT_PACKETS",
+		collector.queueBytes:          "SAI_QUEUE_STAT_BYTES",
+		collector.queueDroppedPackets: "SAI_QUEUE_STAT_DROPPED_PACKETS",
+	}
+
+	wredDropCounters := map[string]string{
+		"green":  "SAI_QUEUE_STAT_GREEN_WRED_DROPPED_PACKETS",
+		"yellow": "SAI_QUEUE_STAT_YELLOW_WRED_DROPPED_PACKETS",
+		"red":    "SAI_QUEUE_STAT_RED_WRED_DROPPED_PACKETS",
+	}
+
+	queues, err := resolveNameMap(ctx, redisClient, "COUNTERS_QUEUE_NAME_MAP", "COUNTERS", func(_, index string) bool {
+		return index != ""
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	queueTypes, err := redisClient.HgetAllFromDb(ctx, "COUNTERS_DB", "COUNTERS_QUEUE_TYPE_MAP")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for _, queue := range queues {
+		portName, queueIndex, counters := queue.port, queue.index, queue.fields
+
+		queueType := strings.ToLower(strings.TrimPrefix(queueTypes[queue.oid], "SAI_QUEUE_TYPE_"))
+
+		for desc, field := range queueCounters {
+			value, ok := counters[field]
+			if !ok {
+				continue
+			}
+
+			parsedValue, err := parseFloat(value)
+			if err != nil {
+				continue
+			}
+
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				desc, prometheus.CounterValue, parsedValue, portName, queueIndex, queueType,
+			))
+		}
+
+		// WRED counters are only present on platforms supporting them
+		if value, ok := counters["SAI_QUEUE_STAT_WRED_ECN_MARKED_PACKETS"]; ok {
+			if parsedValue, err := parseFloat(value); err == nil {
+				metrics = append(metrics, prometheus.MustNewConstMetric(
+					collector.queueWredEcnMarked, prometheus.CounterValue, parsedValue, portName, queueIndex,
+				))
+			}
+		}
+
+		for color, field := range wredDropCounters {
+			value, ok := counters[field]
+			if !ok {
+				continue
+			}
+
+			parsedValue, err := parseFloat(value)
+			if err != nil {
+				continue
+			}
+
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.queueWredDropped, prometheus.CounterValue, parsedValue, portName, queueIndex, color,
+			))
+		}
+
+		// The current occupancy is only polled on platforms supporting it, it
+		// helps to correlate microbursts with drops
+		if value, ok := counters["SAI_QUEUE_STAT_CURR_OCCUPANCY_BYTES"]; ok {
+			if parsedValue, err := parseFloat(value); err == nil {
+				metrics = append(metrics, prometheus.MustNewConstMetric(
+					collector.queueOccupancyBytes, prometheus.GaugeValue, parsedValue, portName, queueIndex,
+				))
+			}
+		}
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,119 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type rebootCauseCollector struct {
+	*scrapeCache
+	rebootCauseInfo  *prometheus.Desc
+	rebootUnexpected *prometheus.Desc
+	redisClient      *redis.Client
+}
+
+func NewRebootCauseCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *rebootCauseCollector {
+	const subsystem = "reboot"
+	namespace := config.namespace()
+
+	collector := &rebootCauseCollector{
+		rebootCauseInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "cause_info"),
+			"Cause of the most recent reboot, value is always 1", []string{"cause", "time", "user"}, nil),
+		rebootUnexpected: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "unexpected"),
+			"Whether the most recent reboot was not a planned reboot: 0(PLANNED), 1(UNEXPECTED)", nil, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "reboot cause", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *rebootCauseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.rebootCauseInfo
+	ch <- collector.rebootUnexpected
+	collector.describeScrape(ch)
+}
+
+func (collector *rebootCauseCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	rebootCauseMetrics, err := collector.collectRebootCause(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("reboot cause collection failed: %w", err)
+	}
+	metrics = append(metrics, rebootCauseMetrics...)
+
+	return metrics, nil
+}
+
+// plannedRebootCauses are the causes of reboots issued on purpose
+var plannedRebootCauses = []string{"reboot", "cold reboot", "warm-reboot", "fast-reboot", "soft-reboot", "express-reboot"}
+
+// collectRebootCause reports the most recent entry of the reboot cause history.
+// Keys are suffixed with the reboot timestamp, the time field is preferred to
+// order them and the key suffix is used where it cannot be parsed.
+func (collector *rebootCauseCollector) collectRebootCause(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const rebootCauseKeyPattern string = "REBOOT_CAUSE|*"
+
+	rebootCauseKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", rebootCauseKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	var (
+		latest     map[string]string
+		latestTime time.Time
+	)
+
+	rebootCauseData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", rebootCauseKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for rebootCauseKey, data := range rebootCauseData {
+		rebootTime, err := time.Parse(time.UnixDate, data["time"])
+		if err != nil {
+			rebootTime, err = time.Parse("2006_01_02_15_04_05", strings.TrimPrefix(rebootCauseKey, "REBOOT_CAUSE|"))
+			if err != nil {
+				continue
+			}
+		}
+
+		if latest == nil || rebootTime.After(latestTime) {
+			latest = data
+			latestTime = rebootTime
+		}
+	}
+
+	if latest == nil {
+		return metrics, nil
+	}
+
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.rebootCauseInfo, prometheus.GaugeValue, 1, latest["cause"], latest["time"], latest["user"],
+	))
+
+	unexpected := 1.0
+	cause := strings.ToLower(latest["cause"])
+	for _, plannedCause := range plannedRebootCauses {
+		if cause == plannedCause || strings.HasPrefix(cause, plannedCause+" ") {
+			unexpected = 0
+			break
+		}
+	}
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.rebootUnexpected, prometheus.GaugeValue, unexpected,
+	))
+
+	return metrics, nil
+}
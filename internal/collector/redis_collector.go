@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type redisCollector struct {
+	*scrapeCache
+	redisServerUptimeSeconds *prometheus.Desc
+	redisServerInfo          *prometheus.Desc
+	redisClient              *redis.Client
+}
+
+func NewRedisCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *redisCollector {
+	const subsystem = "redis"
+	namespace := config.namespace()
+
+	collector := &redisCollector{
+		redisServerUptimeSeconds: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "server_uptime_seconds"),
+			"Number of seconds since the redis server serving the database started", []string{"db"}, nil),
+		redisServerInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "server_info"),
+			"Redis server version, value is always 1", []string{"db", "redis_version"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "redis", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *redisCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.redisServerUptimeSeconds
+	ch <- collector.redisServerInfo
+	collector.describeScrape(ch)
+}
+
+func (collector *redisCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	serverInfoMetrics, err := collector.collectServerInfo(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("redis server info collection failed: %w", err)
+	}
+	metrics = append(metrics, serverInfoMetrics...)
+
+	return metrics, nil
+}
+
+// redisDatabases are the databases read by the other collectors
+var redisDatabases = []string{"APPL_DB", "COUNTERS_DB", "CONFIG_DB", "STATE_DB"}
+
+func (collector *redisCollector) collectServerInfo(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	for _, db := range redisDatabases {
+		info, err := redisClient.InfoFromDb(ctx, db, "server")
+		if err != nil {
+			return nil, fmt.Errorf("redis read failed: %w", err)
+		}
+
+		serverInfoMetrics, err := collector.serverInfoMetrics(db, info)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, serverInfoMetrics...)
+	}
+
+	return metrics, nil
+}
+
+// serverInfoMetrics converts the fields of the INFO server section of one database
+func (collector *redisCollector) serverInfoMetrics(db string, info map[string]string) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	if version, ok := info["redis_version"]; ok {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.redisServerInfo, prometheus.GaugeValue, 1, db, version,
+		))
+	}
+
+	if uptime, ok := info["uptime_in_seconds"]; ok {
+		parsedValue, err := parseFloat(uptime)
+		if err != nil {
+			return nil, fmt.Errorf("value parse failed: %w", err)
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.redisServerUptimeSeconds, prometheus.GaugeValue, parsedValue, db,
+		))
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// redisCommandMetrics records the duration and errors of the redis commands
+// issued by the collectors and the reconnects of the client. Its Observe and
+// ObserveReconnect methods are installed as the observers of a redis client.
+type redisCommandMetrics struct {
+	commandDuration *prometheus.HistogramVec
+	commandErrors   *prometheus.CounterVec
+	reconnects      *prometheus.CounterVec
+}
+
+func NewRedisCommandMetrics(config Config) *redisCommandMetrics {
+	const subsystem = "redis"
+	namespace := config.namespace()
+
+	return &redisCommandMetrics{
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "command_duration_seconds",
+			Help:      "Duration of the redis commands issued by the exporter",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"db", "command"}),
+		commandErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "command_errors_total",
+			Help:      "Number of redis commands issued by the exporter that failed",
+		}, []string{"db", "command"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "reconnects_total",
+			Help:      "Number of connections to a redis database re-established after a connection broke",
+		}, []string{"db"}),
+	}
+}
+
+// Observe records a single redis command
+func (metrics *redisCommandMetrics) Observe(dbName, command string, duration time.Duration, err error) {
+	metrics.commandDuration.WithLabelValues(dbName, command).Observe(duration.Seconds())
+	if err != nil {
+		metrics.commandErrors.WithLabelValues(dbName, command).Inc()
+	}
+}
+
+// ObserveReconnect records a reconnect to a database
+func (metrics *redisCommandMetrics) ObserveReconnect(dbName string) {
+	metrics.reconnects.WithLabelValues(dbName).Inc()
+}
+
+func (metrics *redisCommandMetrics) Describe(ch chan<- *prometheus.Desc) {
+	metrics.commandDuration.Describe(ch)
+	metrics.commandErrors.Describe(ch)
+	metrics.reconnects.Describe(ch)
+}
+
+func (metrics *redisCommandMetrics) Collect(ch chan<- prometheus.Metric) {
+	metrics.commandDuration.Collect(ch)
+	metrics.commandErrors.Collect(ch)
+	metrics.reconnects.Collect(ch)
+}
@@ -0,0 +1,125 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type routeCollector struct {
+	*scrapeCache
+	routeEntries *prometheus.Desc
+	redisClient  *redis.Client
+}
+
+func NewRouteCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *routeCollector {
+	const subsystem = "route"
+	namespace := config.namespace()
+
+	collector := &routeCollector{
+		routeEntries: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "entries"),
+			"Number of installed routes of a VRF and address family", []string{"vrf", "family"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "route", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *routeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.routeEntries
+	collector.describeScrape(ch)
+}
+
+func (collector *routeCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	routeEntriesMetrics, err := collector.collectRouteEntries(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("route collection failed: %w", err)
+	}
+	metrics = append(metrics, routeEntriesMetrics...)
+
+	return metrics, nil
+}
+
+type routeTableKey struct {
+	vrf    string
+	family string
+}
+
+// collectRouteEntries counts the ROUTE_TABLE:<vrf>:<prefix> entries, the VRF is
+// omitted from the key for the default VRF. Keys whose prefix cannot be parsed
+// are skipped.
+func (collector *routeCollector) collectRouteEntries(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const routeKeyPrefix string = "ROUTE_TABLE:"
+
+	routeKeys, err := redisClient.KeysFromDb(ctx, "APPL_DB", routeKeyPrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	routeEntries := make(map[routeTableKey]int)
+
+	for _, routeKey := range routeKeys {
+		vrf, family, ok := parseRouteKey(strings.TrimPrefix(routeKey, routeKeyPrefix))
+		if !ok {
+			continue
+		}
+
+		routeEntries[routeTableKey{vrf: vrf, family: family}]++
+	}
+
+	for key, entries := range routeEntries {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.routeEntries, prometheus.GaugeValue, float64(entries), key.vrf, key.family,
+		))
+	}
+
+	return metrics, nil
+}
+
+// parseRouteKey returns the VRF and address family of a route key without its
+// table prefix. IPv6 prefixes contain the separator, so the whole key is tried
+// as a prefix of the default VRF first.
+func parseRouteKey(key string) (string, string, bool) {
+	vrf, prefix := "default", key
+
+	ip := parseRoutePrefix(prefix)
+	if ip == nil {
+		var ok bool
+		vrf, prefix, ok = strings.Cut(key, ":")
+		if !ok {
+			return "", "", false
+		}
+
+		ip = parseRoutePrefix(prefix)
+		if ip == nil {
+			return "", "", false
+		}
+	}
+
+	if ip.To4() != nil {
+		return vrf, "ipv4", true
+	}
+	return vrf, "ipv6", true
+}
+
+// parseRoutePrefix returns the address of a route prefix. Host routes are
+// keyed by their address without a prefix length, e.g. ROUTE_TABLE:10.1.0.32.
+func parseRoutePrefix(prefix string) net.IP {
+	if ip, _, err := net.ParseCIDR(prefix); err == nil {
+		return ip
+	}
+
+	return net.ParseIP(prefix)
+}
@@ -0,0 +1,140 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type sensorCollector struct {
+	*scrapeCache
+	sensorVoltage          *prometheus.Desc
+	sensorVoltageThreshold *prometheus.Desc
+	sensorCurrent          *prometheus.Desc
+	sensorCurrentThreshold *prometheus.Desc
+	redisClient            *redis.Client
+}
+
+func NewSensorCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *sensorCollector {
+	const subsystem = "sensor"
+	namespace := config.namespace()
+
+	collector := &sensorCollector{
+		sensorVoltage: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "voltage_volts"),
+			"Voltage reported by a board voltage sensor", []string{"sensor"}, nil),
+		sensorVoltageThreshold: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "voltage_threshold_volts"),
+			"Voltage threshold of a board voltage sensor", []string{"sensor", "threshold"}, nil),
+		sensorCurrent: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "current_amperes"),
+			"Current reported by a board current sensor", []string{"sensor"}, nil),
+		sensorCurrentThreshold: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "current_threshold_amperes"),
+			"Current threshold of a board current sensor", []string{"sensor", "threshold"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "sensor", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *sensorCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.sensorVoltage
+	ch <- collector.sensorVoltageThreshold
+	ch <- collector.sensorCurrent
+	ch <- collector.sensorCurrentThreshold
+	collector.describeScrape(ch)
+}
+
+func (collector *sensorCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	voltageSensorsMetrics, err := collector.collectVoltageSensors(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("voltage sensor collection failed: %w", err)
+	}
+	metrics = append(metrics, voltageSensorsMetrics...)
+
+	currentSensorsMetrics, err := collector.collectCurrentSensors(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("current sensor collection failed: %w", err)
+	}
+	metrics = append(metrics, currentSensorsMetrics...)
+
+	return metrics, nil
+}
+
+// sensorThresholds maps the threshold fields of VOLTAGE_INFO and CURRENT_INFO to the threshold label
+var sensorThresholds = map[string]string{
+	"high_threshold":          "high",
+	"low_threshold":           "low",
+	"critical_high_threshold": "critical_high",
+	"critical_low_threshold":  "critical_low",
+}
+
+// collectVoltageSensors reads the board voltage rails from VOLTAGE_INFO|<sensor>.
+// Readings are stored in mV unless the unit field says otherwise.
+func (collector *sensorCollector) collectVoltageSensors(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	return collector.collectSensors(ctx, redisClient, "VOLTAGE_INFO", "voltage", "mV",
+		collector.sensorVoltage, collector.sensorVoltageThreshold)
+}
+
+// collectCurrentSensors reads the board current sensors from CURRENT_INFO|<sensor>.
+// Readings are stored in mA unless the unit field says otherwise.
+func (collector *sensorCollector) collectCurrentSensors(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	return collector.collectSensors(ctx, redisClient, "CURRENT_INFO", "current", "mA",
+		collector.sensorCurrent, collector.sensorCurrentThreshold)
+}
+
+// collectSensors reads the value field and thresholds of every sensor in table
+// and converts milli units to base units, a unit appended to a reading is
+// ignored. Missing or unparsable values, e.g. N/A, yield no series.
+func (collector *sensorCollector) collectSensors(ctx context.Context, redisClient *redis.Client, table, valueField, defaultUnit string, valueDesc, thresholdDesc *prometheus.Desc) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	sensorKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", table+"|*")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	sensorData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", sensorKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for sensorKey, data := range sensorData {
+		sensorName := strings.TrimPrefix(sensorKey, table+"|")
+
+		unit := defaultUnit
+		if value, ok := data["unit"]; ok {
+			unit = value
+		}
+
+		divisor := 1.0
+		if strings.HasPrefix(unit, "m") {
+			divisor = 1000
+		}
+
+		if parsedValue, ok, err := parseMeasurement(data[valueField]); err == nil && ok {
+			metrics = append(metrics, derivedGauge(
+				valueDesc, parsedValue/divisor, collector.config.Precision, sensorName,
+			))
+		}
+
+		for field, threshold := range sensorThresholds {
+			parsedValue, ok, err := parseMeasurement(data[field])
+			if err != nil || !ok {
+				continue
+			}
+
+			metrics = append(metrics, derivedGauge(
+				thresholdDesc, parsedValue/divisor, collector.config.Precision, sensorName, threshold,
+			))
+		}
+	}
+
+	return metrics, nil
+}
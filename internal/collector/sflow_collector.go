@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type sflowCollector struct {
+	*scrapeCache
+	sflowAdminState *prometheus.Desc
+	sflowSampleRate *prometheus.Desc
+	sflowEnabled    *prometheus.Desc
+	redisClient     *redis.Client
+}
+
+func NewSflowCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *sflowCollector {
+	const subsystem = "sflow"
+	namespace := config.namespace()
+
+	collector := &sflowCollector{
+		sflowAdminState: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "admin_state"),
+			"Whether sFlow is enabled globally: 0(DOWN), 1(UP)", nil, nil),
+		sflowSampleRate: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "sample_rate"),
+			"sFlow sampling rate of an interface, one in sample_rate packets is sampled", []string{"device"}, nil),
+		sflowEnabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "enabled"),
+			"Whether sFlow sampling is active on an interface, taking the global admin state into account", []string{"device"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "sflow", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *sflowCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.sflowAdminState
+	ch <- collector.sflowSampleRate
+	ch <- collector.sflowEnabled
+	collector.describeScrape(ch)
+}
+
+func (collector *sflowCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	sflowMetrics, err := collector.collectSflow(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("sflow collection failed: %w", err)
+	}
+	metrics = append(metrics, sflowMetrics...)
+
+	return metrics, nil
+}
+
+// collectSflow reads the global sFlow admin state from SFLOW|global and the
+// interface sessions from SFLOW_SESSION|<port> in CONFIG_DB. sFlow is disabled
+// unless SFLOW|global is up. Sessions without an admin state inherit the one of
+// SFLOW_SESSION|all, which defaults to up. A session is only sampling while
+// sFlow is enabled globally.
+func (collector *sflowCollector) collectSflow(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const sessionKeyPattern string = "SFLOW_SESSION|*"
+
+	globalData, err := redisClient.HgetAllFromDb(ctx, "CONFIG_DB", "SFLOW|global")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	globalUp := strings.ToLower(globalData["admin_state"]) == "up"
+	adminStateValue := 0.0
+	if globalUp {
+		adminStateValue = 1
+	}
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.sflowAdminState, prometheus.GaugeValue, adminStateValue,
+	))
+
+	allData, err := redisClient.HgetAllFromDb(ctx, "CONFIG_DB", "SFLOW_SESSION|all")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	defaultAdminState := "up"
+	if adminState, ok := allData["admin_state"]; ok {
+		defaultAdminState = strings.ToLower(adminState)
+	}
+
+	sessionKeys, err := redisClient.KeysFromDb(ctx, "CONFIG_DB", sessionKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	sessionData, err := redisClient.HgetAllPipelined(ctx, "CONFIG_DB", sessionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for sessionKey, data := range sessionData {
+		interfaceName := strings.TrimPrefix(sessionKey, "SFLOW_SESSION|")
+		if interfaceName == "all" {
+			continue
+		}
+
+		adminState := defaultAdminState
+		if state, ok := data["admin_state"]; ok {
+			adminState = strings.ToLower(state)
+		}
+
+		enabled := 0.0
+		if globalUp && adminState == "up" {
+			enabled = 1
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.sflowEnabled, prometheus.GaugeValue, enabled, interfaceName,
+		))
+
+		sampleRate, ok := data["sample_rate"]
+		if !ok {
+			continue
+		}
+
+		parsedRate, err := parseFloat(sampleRate)
+		if err != nil {
+			return nil, fmt.Errorf("value parse failed: %w", err)
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.sflowSampleRate, prometheus.GaugeValue, parsedRate, interfaceName,
+		))
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type stormControlCollector struct {
+	*scrapeCache
+	stormControlRate *prometheus.Desc
+	redisClient      *redis.Client
+}
+
+func NewStormControlCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *stormControlCollector {
+	const subsystem = "storm_control"
+	namespace := config.namespace()
+
+	collector := &stormControlCollector{
+		stormControlRate: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "rate_bytes"),
+			"Storm control rate limit of a traffic type on an interface in bytes per second", []string{"device", "type"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "storm control", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *stormControlCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.stormControlRate
+	collector.describeScrape(ch)
+}
+
+func (collector *stormControlCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	stormControlMetrics, err := collector.collectStormControl(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("storm control collection failed: %w", err)
+	}
+	metrics = append(metrics, stormControlMetrics...)
+
+	return metrics, nil
+}
+
+// collectStormControl reads the storm control configuration of the ports from
+// PORT_STORM_CONTROL|<port>|<type>, type being broadcast, unknown-multicast or
+// unknown-unicast. Traffic types without or with disabled storm control yield
+// no series. The rate is configured in kbps.
+func (collector *stormControlCollector) collectStormControl(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const stormControlKeyPattern string = "PORT_STORM_CONTROL|*"
+
+	stormControlKeys, err := redisClient.KeysFromDb(ctx, "CONFIG_DB", stormControlKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	stormControlData, err := redisClient.HgetAllPipelined(ctx, "CONFIG_DB", stormControlKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for stormControlKey, data := range stormControlData {
+		keyParts := strings.SplitN(stormControlKey, "|", 3)
+		if len(keyParts) != 3 {
+			continue
+		}
+		interfaceName, trafficType := keyParts[1], keyParts[2]
+
+		if enabled, ok := data["enabled"]; ok && strings.ToLower(enabled) != "true" {
+			continue
+		}
+
+		kbps, err := parseFloat(data["kbps"])
+		if err != nil {
+			return nil, fmt.Errorf("value parse failed: %w", err)
+		}
+
+		metrics = append(metrics, derivedGauge(
+			collector.stormControlRate, kbps*1000/8, collector.config.Precision, interfaceName, trafficType,
+		))
+	}
+
+	return metrics, nil
+}
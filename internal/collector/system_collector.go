@@ -0,0 +1,244 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type systemCollector struct {
+	*scrapeCache
+	systemCpuUtilization  *prometheus.Desc
+	systemMemoryUsed      *prometheus.Desc
+	systemMemoryTotal     *prometheus.Desc
+	systemUptimeSeconds   *prometheus.Desc
+	systemReady           *prometheus.Desc
+	systemServiceNotReady *prometheus.Desc
+	redisClient           *redis.Client
+}
+
+func NewSystemCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *systemCollector {
+	const subsystem = "system"
+	namespace := config.namespace()
+
+	collector := &systemCollector{
+		systemCpuUtilization: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "cpu_utilization_ratio"),
+			"Share of CPU time spent in user and system mode", nil, nil),
+		systemMemoryUsed: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "memory_used_bytes"),
+			"Memory used by the system", nil, nil),
+		systemMemoryTotal: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "memory_total_bytes"),
+			"Total memory of the system", nil, nil),
+		systemUptimeSeconds: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "uptime_seconds"),
+			"Number of seconds since the system booted", nil, nil),
+		systemReady: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "ready"),
+			"Whether system monitor reports the system ready: 0(DOWN), 1(UP)", nil, nil),
+		systemServiceNotReady: prometheus.NewDesc(prometheus.BuildFQName(namespace, "services", "not_ready"),
+			"Service reported not ready by system monitor, value is always 1", []string{"service"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "system", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *systemCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.systemCpuUtilization
+	ch <- collector.systemMemoryUsed
+	ch <- collector.systemMemoryTotal
+	ch <- collector.systemUptimeSeconds
+	ch <- collector.systemReady
+	ch <- collector.systemServiceNotReady
+	collector.describeScrape(ch)
+}
+
+func (collector *systemCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	cpuStatsMetrics, err := collector.collectCpuStats(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("system cpu collection failed: %w", err)
+	}
+	metrics = append(metrics, cpuStatsMetrics...)
+
+	memoryStatsMetrics, err := collector.collectMemoryStats(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("system memory collection failed: %w", err)
+	}
+	metrics = append(metrics, memoryStatsMetrics...)
+
+	uptimeMetrics, err := collector.collectUptime()
+	if err != nil {
+		return nil, fmt.Errorf("system uptime collection failed: %w", err)
+	}
+	metrics = append(metrics, uptimeMetrics...)
+
+	readyMetrics, err := collector.collectSystemReady(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("system ready collection failed: %w", err)
+	}
+	metrics = append(metrics, readyMetrics...)
+
+	return metrics, nil
+}
+
+// collectCpuStats reads the psutil fed SYSTEM_STATS|CPU hash, USER_CPU and
+// SYSTEM_CPU are reported in percent. Devices not publishing it yield no series.
+func (collector *systemCollector) collectCpuStats(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "SYSTEM_STATS|CPU")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	userCpu, userOk := data["USER_CPU"]
+	systemCpu, systemOk := data["SYSTEM_CPU"]
+	if !userOk && !systemOk {
+		return metrics, nil
+	}
+
+	userPercent, err := parseFloat(userCpu)
+	if err != nil {
+		return nil, fmt.Errorf("value parse failed: %w", err)
+	}
+
+	systemPercent, err := parseFloat(systemCpu)
+	if err != nil {
+		return nil, fmt.Errorf("value parse failed: %w", err)
+	}
+
+	metrics = append(metrics, derivedGauge(
+		collector.systemCpuUtilization, (userPercent+systemPercent)/100, collector.config.Precision,
+	))
+
+	return metrics, nil
+}
+
+// collectMemoryStats reads the psutil fed SYSTEM_STATS|MEMORY hash, values are
+// reported in bytes. Devices not publishing it yield no series.
+func (collector *systemCollector) collectMemoryStats(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "SYSTEM_STATS|MEMORY")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	memoryFields := map[string]*prometheus.Desc{
+		"used":  collector.systemMemoryUsed,
+		"total": collector.systemMemoryTotal,
+	}
+
+	for field, desc := range memoryFields {
+		value, ok := data[field]
+		if !ok {
+			continue
+		}
+
+		parsedValue, err := parseFloat(value)
+		if err != nil {
+			return nil, fmt.Errorf("value parse failed: %w", err)
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			desc, prometheus.GaugeValue, parsedValue,
+		))
+	}
+
+	return metrics, nil
+}
+
+// collectUptime reads the uptime from the first field of /proc/uptime. A
+// missing file or an empty path yield no series.
+func (collector *systemCollector) collectUptime() ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	if collector.config.UptimeFile == "" {
+		return metrics, nil
+	}
+
+	data, err := os.ReadFile(collector.config.UptimeFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return metrics, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("malformed uptime file %s", collector.config.UptimeFile)
+	}
+
+	uptime, err := parseFloat(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("value parse failed: %w", err)
+	}
+
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.systemUptimeSeconds, prometheus.GaugeValue, uptime,
+	))
+
+	return metrics, nil
+}
+
+// collectSystemReady reads the system state of system monitor from
+// SYSTEM_READY|SYSTEM_STATE and the services it reports not OK in
+// ALL_SERVICE_STATUS. Images without system monitor yield no series.
+func (collector *systemCollector) collectSystemReady(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const (
+		systemReadyKey          string = "SYSTEM_READY|SYSTEM_STATE"
+		serviceStatusKeyPattern string = "ALL_SERVICE_STATUS|*"
+	)
+
+	systemState, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", systemReadyKey)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	if status, ok := systemState["Status"]; ok {
+		ready := 0.0
+		if strings.ToUpper(status) == "UP" {
+			ready = 1.0
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.systemReady, prometheus.GaugeValue, ready,
+		))
+	}
+
+	serviceKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", serviceStatusKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	serviceData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", serviceKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for _, serviceKey := range serviceKeys {
+		service := strings.TrimPrefix(serviceKey, "ALL_SERVICE_STATUS|")
+
+		data := serviceData[serviceKey]
+		if strings.ToUpper(data["service_status"]) == "OK" && strings.ToUpper(data["app_ready_status"]) != "DOWN" {
+			continue
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.systemServiceNotReady, prometheus.GaugeValue, 1, service,
+		))
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,278 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// transceiverThreshold is a threshold field of TRANSCEIVER_DOM_THRESHOLD and the
+// scale converting its value to the base unit of desc
+type transceiverThreshold struct {
+	desc  *prometheus.Desc
+	scale float64
+}
+
+type transceiverCollector struct {
+	*scrapeCache
+	transceiverPowerClassInfo *prometheus.Desc
+	transceiverMaxPowerWatts  *prometheus.Desc
+	transceiverRxLos          *prometheus.Desc
+	transceiverTxFault        *prometheus.Desc
+	transceiverTxLol          *prometheus.Desc
+	transceiverThresholds     map[string]transceiverThreshold
+	redisClient               *redis.Client
+}
+
+func NewTransceiverCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *transceiverCollector {
+	const subsystem = "transceiver"
+	namespace := config.namespace()
+
+	collector := &transceiverCollector{
+		transceiverPowerClassInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "power_class_info"),
+			"Transceiver power class, value is always 1", []string{"device", "power_class"}, nil),
+		transceiverMaxPowerWatts: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "max_power_watts"),
+			"Transceiver maximum power consumption", []string{"device"}, nil),
+		transceiverRxLos: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "rx_los"),
+			"Whether a transceiver lane reports loss of signal on receive", []string{"device", "lane"}, nil),
+		transceiverTxFault: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "tx_fault"),
+			"Whether a transceiver lane reports a transmitter fault", []string{"device", "lane"}, nil),
+		transceiverTxLol: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "tx_lol"),
+			"Whether a transceiver lane reports loss of lock on transmit", []string{"device", "lane"}, nil),
+		transceiverThresholds: newTransceiverThresholds(namespace, subsystem),
+		redisClient:           redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "transceiver", collector.scrapeMetrics)
+
+	return collector
+}
+
+// newTransceiverThresholds returns the thresholds of TRANSCEIVER_DOM_THRESHOLD by
+// field name, e.g. temphighalarm or txbiaslowwarning. Each quantity has a high
+// and low alarm and warning threshold.
+func newTransceiverThresholds(namespace, subsystem string) map[string]transceiverThreshold {
+	quantities := []struct {
+		field, name, unit, help string
+		scale                   float64
+	}{
+		{"temp", "temperature", "celsius", "temperature", 1},
+		{"vcc", "voltage", "volts", "supply voltage", 1},
+		{"txpower", "tx_power", "dbm", "transmit power", 1},
+		{"rxpower", "rx_power", "dbm", "receive power", 1},
+		// bias currents are reported in mA
+		{"txbias", "tx_bias", "amperes", "transmit bias current", 0.001},
+	}
+
+	levels := []struct{ field, name, help string }{
+		{"highalarm", "high_alarm", "high alarm"},
+		{"highwarning", "high_warning", "high warning"},
+		{"lowalarm", "low_alarm", "low alarm"},
+		{"lowwarning", "low_warning", "low warning"},
+	}
+
+	thresholds := make(map[string]transceiverThreshold, len(quantities)*len(levels))
+	for _, quantity := range quantities {
+		for _, level := range levels {
+			thresholds[quantity.field+level.field] = transceiverThreshold{
+				desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, quantity.name+"_"+level.name+"_"+quantity.unit),
+					fmt.Sprintf("Transceiver %s %s threshold specified by the module", quantity.help, level.help), []string{"device"}, nil),
+				scale: quantity.scale,
+			}
+		}
+	}
+
+	return thresholds
+}
+
+func (collector *transceiverCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.transceiverPowerClassInfo
+	ch <- collector.transceiverMaxPowerWatts
+	ch <- collector.transceiverRxLos
+	ch <- collector.transceiverTxFault
+	ch <- collector.transceiverTxLol
+	for _, threshold := range collector.transceiverThresholds {
+		ch <- threshold.desc
+	}
+	collector.describeScrape(ch)
+}
+
+func (collector *transceiverCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	transceiverPowerInfoMetrics, err := collector.collectTransceiverPowerInfo(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("transceiver power info collection failed: %w", err)
+	}
+	metrics = append(metrics, transceiverPowerInfoMetrics...)
+
+	transceiverFlagMetrics, err := collector.collectTransceiverFlags(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("transceiver flag collection failed: %w", err)
+	}
+	metrics = append(metrics, transceiverFlagMetrics...)
+
+	transceiverThresholdMetrics, err := collector.collectTransceiverThresholds(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("transceiver threshold collection failed: %w", err)
+	}
+	metrics = append(metrics, transceiverThresholdMetrics...)
+
+	return metrics, nil
+}
+
+// collectTransceiverPowerInfo reads the power class and maximum power of each
+// plugged optic. Platforms without dedicated fields encode both in
+// ext_identifier, e.g. "Power Class 8 (20.0W Max)".
+func (collector *transceiverCollector) collectTransceiverPowerInfo(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const transceiverKeyPattern string = "TRANSCEIVER_INFO|*"
+	powerClassRegex := regexp.MustCompile(`(?i)power class (\d+)`)
+	maxPowerRegex := regexp.MustCompile(`(?i)([\d.]+)\s*W\s*max`)
+
+	transceiverKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", transceiverKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	transceiverData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", transceiverKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for transceiverKey, data := range transceiverData {
+		interfaceName := strings.Split(transceiverKey, "|")[1]
+
+		powerClass := data["power_class"]
+		if powerClass == "" && powerClassRegex.MatchString(data["ext_identifier"]) {
+			powerClass = powerClassRegex.FindStringSubmatch(data["ext_identifier"])[1]
+		}
+
+		if powerClass != "" && powerClass != "N/A" {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				collector.transceiverPowerClassInfo, prometheus.GaugeValue, 1, interfaceName, powerClass,
+			))
+		}
+
+		maxPower := data["max_power"]
+		if maxPower == "" && maxPowerRegex.MatchString(data["ext_identifier"]) {
+			maxPower = maxPowerRegex.FindStringSubmatch(data["ext_identifier"])[1]
+		}
+
+		// max power is appended only if the value can be parsed
+		maxPowerWatts, ok, err := parseMeasurement(maxPower)
+		if err == nil && ok {
+			metrics = append(metrics, derivedGauge(
+				collector.transceiverMaxPowerWatts, maxPowerWatts, collector.config.Precision, interfaceName,
+			))
+		}
+	}
+
+	return metrics, nil
+}
+
+// collectTransceiverFlags reads the per-lane fault flags of each plugged optic
+// from TRANSCEIVER_DOM_FLAG, e.g. rx3los or tx2fault. Modules that don't
+// populate a flag report it as N/A or leave it out, such flags are skipped.
+func (collector *transceiverCollector) collectTransceiverFlags(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const flagKeyPattern string = "TRANSCEIVER_DOM_FLAG|*"
+	laneFlagRegex := regexp.MustCompile(`^(rx|tx)(\d+)(los|fault|lol)$`)
+
+	laneFlags := map[string]*prometheus.Desc{
+		"rxlos":   collector.transceiverRxLos,
+		"txfault": collector.transceiverTxFault,
+		"txlol":   collector.transceiverTxLol,
+	}
+
+	flagKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", flagKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	flagData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", flagKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for flagKey, data := range flagData {
+		interfaceName := strings.Split(flagKey, "|")[1]
+
+		for field, value := range data {
+			match := laneFlagRegex.FindStringSubmatch(field)
+			if match == nil {
+				continue
+			}
+
+			desc, ok := laneFlags[match[1]+match[3]]
+			if !ok {
+				continue
+			}
+
+			var flag float64
+			switch strings.ToLower(value) {
+			case "true":
+				flag = 1
+			case "false":
+				flag = 0
+			default:
+				continue
+			}
+
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				desc, prometheus.GaugeValue, flag, interfaceName, match[2],
+			))
+		}
+	}
+
+	return metrics, nil
+}
+
+// collectTransceiverThresholds reads the alarm and warning thresholds the vendor
+// programmed into each plugged optic from TRANSCEIVER_DOM_THRESHOLD. Only
+// thresholds the module reports are exported.
+func (collector *transceiverCollector) collectTransceiverThresholds(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const thresholdKeyPattern string = "TRANSCEIVER_DOM_THRESHOLD|*"
+
+	thresholdKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", thresholdKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	thresholdData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", thresholdKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for thresholdKey, data := range thresholdData {
+		interfaceName := strings.Split(thresholdKey, "|")[1]
+
+		for field, value := range data {
+			threshold, ok := collector.transceiverThresholds[field]
+			if !ok {
+				continue
+			}
+
+			parsedValue, ok, err := parseMeasurement(value)
+			if err != nil || !ok {
+				continue
+			}
+
+			metrics = append(metrics, derivedGauge(
+				threshold.desc, parsedValue*threshold.scale, collector.config.Precision, interfaceName,
+			))
+		}
+	}
+
+	return metrics, nil
+}
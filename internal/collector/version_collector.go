@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type versionCollector struct {
+	*scrapeCache
+	versionInfo *prometheus.Desc
+	redisClient *redis.Client
+}
+
+func NewVersionCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *versionCollector {
+	const subsystem = "version"
+	namespace := config.namespace()
+
+	collector := &versionCollector{
+		versionInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "info"),
+			"SONiC image version and platform, value is always 1", []string{"version", "platform", "hwsku", "kernel", "asic_type"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "version", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *versionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.versionInfo
+	collector.describeScrape(ch)
+}
+
+func (collector *versionCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	versionInfoMetrics, err := collector.collectVersionInfo(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("version info collection failed: %w", err)
+	}
+	metrics = append(metrics, versionInfoMetrics...)
+
+	return metrics, nil
+}
+
+// collectVersionInfo combines the platform from DEVICE_METADATA with the image
+// version SONiC records in sonic_version.yml. Fields that are not available
+// are reported as empty labels.
+func (collector *versionCollector) collectVersionInfo(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	metadata, err := redisClient.HgetAllFromDb(ctx, "CONFIG_DB", "DEVICE_METADATA|localhost")
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	sonicVersion, err := readSonicVersion(collector.config.VersionFile)
+	if err != nil {
+		return nil, fmt.Errorf("version file read failed: %w", err)
+	}
+
+	asicType := metadata["asic_type"]
+	if asicType == "" {
+		asicType = sonicVersion["asic_type"]
+	}
+
+	metrics = append(metrics, prometheus.MustNewConstMetric(
+		collector.versionInfo, prometheus.GaugeValue, 1,
+		sonicVersion["build_version"], metadata["platform"], metadata["hwsku"], sonicVersion["kernel_version"], asicType,
+	))
+
+	return metrics, nil
+}
+
+// readSonicVersion reads the flat "key: 'value'" entries of sonic_version.yml.
+// A missing file or an empty path yield no entries.
+func readSonicVersion(path string) (map[string]string, error) {
+	data := make(map[string]string)
+
+	if path == "" {
+		return data, nil
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.HasPrefix(key, " ") || strings.HasPrefix(key, "#") {
+			continue
+		}
+
+		data[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `'"`)
+	}
+
+	return data, scanner.Err()
+}
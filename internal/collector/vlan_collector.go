@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type vlanCollector struct {
+	*scrapeCache
+	vlanInfo    *prometheus.Desc
+	vlanMember  *prometheus.Desc
+	redisClient *redis.Client
+}
+
+func NewVlanCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *vlanCollector {
+	const subsystem = "vlan"
+	namespace := config.namespace()
+
+	collector := &vlanCollector{
+		vlanInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "info"),
+			"Configured VLAN, value is always 1", []string{"vlan"}, nil),
+		vlanMember: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "member"),
+			"VLAN membership of a port, value is always 1", []string{"vlan", "device", "tagging_mode"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "vlan", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *vlanCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.vlanInfo
+	ch <- collector.vlanMember
+	collector.describeScrape(ch)
+}
+
+func (collector *vlanCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	vlansMetrics, err := collector.collectVlans(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("vlan collection failed: %w", err)
+	}
+	metrics = append(metrics, vlansMetrics...)
+
+	return metrics, nil
+}
+
+// collectVlans reads the configured VLANs and their members from CONFIG_DB.
+// Member keys are composite, e.g. VLAN_MEMBER|Vlan100|Ethernet0.
+func (collector *vlanCollector) collectVlans(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const (
+		vlanKeyPattern       string = "VLAN|Vlan*"
+		vlanMemberKeyPattern string = "VLAN_MEMBER|*"
+	)
+
+	vlanKeys, err := redisClient.KeysFromDb(ctx, "CONFIG_DB", vlanKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for _, vlanKey := range vlanKeys {
+		vlanName := strings.TrimPrefix(vlanKey, "VLAN|")
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.vlanInfo, prometheus.GaugeValue, 1, vlanName,
+		))
+	}
+
+	vlanMemberKeys, err := redisClient.KeysFromDb(ctx, "CONFIG_DB", vlanMemberKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	vlanMemberData, err := redisClient.HgetAllPipelined(ctx, "CONFIG_DB", vlanMemberKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for vlanMemberKey, data := range vlanMemberData {
+		keyParts := strings.SplitN(vlanMemberKey, "|", 3)
+		if len(keyParts) != 3 {
+			continue
+		}
+		vlanName, memberName := keyParts[1], keyParts[2]
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.vlanMember, prometheus.GaugeValue, 1, vlanName, memberName, data["tagging_mode"],
+		))
+	}
+
+	return metrics, nil
+}
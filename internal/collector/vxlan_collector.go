@@ -0,0 +1,119 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type vxlanCollector struct {
+	*scrapeCache
+	vxlanTunnelInfo    *prometheus.Desc
+	vxlanTunnelRxBytes *prometheus.Desc
+	vxlanTunnelTxBytes *prometheus.Desc
+	redisClient        *redis.Client
+}
+
+func NewVxlanCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *vxlanCollector {
+	const subsystem = "vxlan"
+	namespace := config.namespace()
+
+	collector := &vxlanCollector{
+		vxlanTunnelInfo: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "tunnel_info"),
+			"VXLAN tunnel to a remote VTEP, value is always 1", []string{"src_ip", "dst_ip"}, nil),
+		vxlanTunnelRxBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "tunnel_rx_bytes_total"),
+			"Number of bytes received through a VXLAN tunnel", []string{"dst_ip"}, nil),
+		vxlanTunnelTxBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "tunnel_tx_bytes_total"),
+			"Number of bytes transmitted through a VXLAN tunnel", []string{"dst_ip"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "vxlan", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *vxlanCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.vxlanTunnelInfo
+	ch <- collector.vxlanTunnelRxBytes
+	ch <- collector.vxlanTunnelTxBytes
+	collector.describeScrape(ch)
+}
+
+func (collector *vxlanCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	tunnelsMetrics, err := collector.collectTunnels(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("vxlan tunnel collection failed: %w", err)
+	}
+	metrics = append(metrics, tunnelsMetrics...)
+
+	return metrics, nil
+}
+
+// collectTunnels reads the tunnels to remote VTEPs, e.g. learned through EVPN,
+// from VXLAN_TUNNEL_TABLE in STATE_DB and their counters through
+// COUNTERS_TUNNEL_NAME_MAP. Tunnels without counters only report info.
+func (collector *vxlanCollector) collectTunnels(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const tunnelKeyPattern string = "VXLAN_TUNNEL_TABLE|*"
+
+	tunnelCounters := map[*prometheus.Desc]string{
+		collector.vxlanTunnelRxBytes: "SAI_TUNNEL_STAT_IN_OCTETS",
+		collector.vxlanTunnelTxBytes: "SAI_TUNNEL_STAT_OUT_OCTETS",
+	}
+
+	tunnelKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", tunnelKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	tunnels, err := resolveNameMap(ctx, redisClient, "COUNTERS_TUNNEL_NAME_MAP", "COUNTERS", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnelData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", tunnelKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for tunnelKey, data := range tunnelData {
+		tunnelName := strings.TrimPrefix(tunnelKey, "VXLAN_TUNNEL_TABLE|")
+
+		dstIp := data["dst_ip"]
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.vxlanTunnelInfo, prometheus.GaugeValue, 1, data["src_ip"], dstIp,
+		))
+
+		tunnel, ok := tunnels[tunnelName]
+		if !ok {
+			continue
+		}
+
+		for desc, field := range tunnelCounters {
+			value, ok := tunnel.fields[field]
+			if !ok {
+				continue
+			}
+
+			parsedValue, err := parseFloat(value)
+			if err != nil {
+				continue
+			}
+
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				desc, prometheus.CounterValue, parsedValue, dstIp,
+			))
+		}
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mwennrich/sonic-exporter/pkg/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// warmRestartStates maps the warm restart state of a process to the value of
+// sonic_warmboot_state, states not listed are reported as -1
+var warmRestartStates = map[string]float64{
+	"disabled":    0,
+	"initialized": 1,
+	"restored":    2,
+	"replayed":    3,
+	"reconciled":  4,
+}
+
+type warmbootCollector struct {
+	*scrapeCache
+	warmbootEnabled *prometheus.Desc
+	warmbootState   *prometheus.Desc
+	redisClient     *redis.Client
+}
+
+func NewWarmbootCollector(logger *slog.Logger, redisClient *redis.Client, config Config) *warmbootCollector {
+	const subsystem = "warmboot"
+	namespace := config.namespace()
+
+	collector := &warmbootCollector{
+		warmbootEnabled: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "enabled"),
+			"Whether warm restart is enabled for a module: 0(DISABLED), 1(ENABLED)", []string{"module"}, nil),
+		warmbootState: prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, "state"),
+			"Warm restart state of a module: -1(UNKNOWN), 0(DISABLED), 1(INITIALIZED), 2(RESTORED), 3(REPLAYED), 4(RECONCILED)", []string{"module"}, nil),
+		redisClient: redisClient,
+	}
+	collector.scrapeCache = newScrapeCache(logger, config, subsystem, "warmboot", collector.scrapeMetrics)
+
+	return collector
+}
+
+func (collector *warmbootCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.warmbootEnabled
+	ch <- collector.warmbootState
+	collector.describeScrape(ch)
+}
+
+func (collector *warmbootCollector) scrapeMetrics(ctx context.Context) ([]prometheus.Metric, error) {
+	redisClient := collector.redisClient
+
+	var metrics []prometheus.Metric
+
+	warmbootEnabledMetrics, err := collector.collectWarmbootEnabled(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("warmboot enable collection failed: %w", err)
+	}
+	metrics = append(metrics, warmbootEnabledMetrics...)
+
+	warmbootStateMetrics, err := collector.collectWarmbootState(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("warmboot state collection failed: %w", err)
+	}
+	metrics = append(metrics, warmbootStateMetrics...)
+
+	return metrics, nil
+}
+
+// collectWarmbootEnabled reads which modules have warm restart enabled
+func (collector *warmbootCollector) collectWarmbootEnabled(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const enableKeyPattern string = "WARM_RESTART_ENABLE_TABLE|*"
+
+	enableKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", enableKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	enableData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", enableKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for enableKey, data := range enableData {
+		module := strings.TrimPrefix(enableKey, "WARM_RESTART_ENABLE_TABLE|")
+
+		enabled := 0.0
+		if strings.ToLower(data["enable"]) == "true" {
+			enabled = 1.0
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.warmbootEnabled, prometheus.GaugeValue, enabled, module,
+		))
+	}
+
+	return metrics, nil
+}
+
+// collectWarmbootState reads the warm restart state of the modules. Modules
+// only have a state once they went through a warm restart, modules that are
+// enabled but have not restarted yet yield no state series.
+func (collector *warmbootCollector) collectWarmbootState(ctx context.Context, redisClient *redis.Client) ([]prometheus.Metric, error) {
+	var metrics []prometheus.Metric
+
+	const stateKeyPattern string = "WARM_RESTART_TABLE|*"
+
+	stateKeys, err := redisClient.KeysFromDb(ctx, "STATE_DB", stateKeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	stateData, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", stateKeys)
+	if err != nil {
+		return nil, fmt.Errorf("redis read failed: %w", err)
+	}
+
+	for stateKey, data := range stateData {
+		module := strings.TrimPrefix(stateKey, "WARM_RESTART_TABLE|")
+
+		state, ok := data["state"]
+		if !ok {
+			continue
+		}
+
+		value, ok := warmRestartStates[strings.ToLower(state)]
+		if !ok {
+			value = -1
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			collector.warmbootState, prometheus.GaugeValue, value, module,
+		))
+	}
+
+	return metrics, nil
+}
@@ -2,62 +2,287 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/redis/go-redis/v9"
 )
 
+// Number of keys requested per SCAN iteration
+const scanCount = 500
+
+// CommandObserver is notified after every command the client issues with the
+// database, the lower case command name, its duration and error
+type CommandObserver func(dbName, command string, duration time.Duration, err error)
+
+// Client lazily connects to the SONiC databases of one redis namespace. It is
+// safe for concurrent use by multiple collectors.
 type Client struct {
 	databases map[string]*redis.Client
 	config    RedisConfig
+	dbConfig  *DatabaseConfig
+	// connect to the instances listed in dbConfig rather than the configured address
+	useInstances bool
+	tlsConfig    *tls.Config
+	observer     CommandObserver
+	// issue KEYS rather than SCAN in KeysFromDb
+	useKeys bool
+	// broken connections per database, which new connections replace
+	brokenConns       map[string]int
+	reconnectObserver ReconnectObserver
+	// hashes read in the current epoch of the read cache
+	readCache      map[readCacheKey]*readCacheEntry
+	readCacheEpoch int64
+	readCacheTTL   time.Duration
+	mu             sync.Mutex
 }
 
-func RedisDbId(name string) (int, bool) {
-	switch name {
-	case "APPL_DB":
-		return 0, true
-	case "COUNTERS_DB":
-		return 2, true
-	case "CONFIG_DB":
-		return 4, true
-	case "STATE_DB":
-		return 6, true
-	}
+// defaultDbIds are the database ids of SONiC's default database_config.json
+var defaultDbIds = map[string]int{
+	"APPL_DB":            0,
+	"ASIC_DB":            1,
+	"COUNTERS_DB":        2,
+	"LOGLEVEL_DB":        3,
+	"CONFIG_DB":          4,
+	"PFC_WD_DB":          5,
+	"FLEX_COUNTER_DB":    5,
+	"STATE_DB":           6,
+	"SNMP_OVERLAY_DB":    7,
+	"RESTAPI_DB":         8,
+	"GB_ASIC_DB":         9,
+	"GB_COUNTERS_DB":     10,
+	"GB_FLEX_COUNTER_DB": 11,
+	"CHASSIS_APP_DB":     12,
+	"CHASSIS_STATE_DB":   13,
+	"APPL_STATE_DB":      14,
+}
 
-	return 0, false
+// RedisDbId returns the default SONiC id of a database, used when no
+// database_config.json is available
+func RedisDbId(name string) (int, bool) {
+	dbId, ok := defaultDbIds[name]
+	return dbId, ok
 }
 
+// RedisConfig holds the connection settings. When Network is "unix" the Socket
+// path is used if set, otherwise Address is treated as the socket path. Setting
+// REDIS_SOCKET implies the unix network.
+//
+// Database ids are read from the SONiC database config. Unless REDIS_ADDRESS
+// or REDIS_SOCKET is set explicitly, the redis instance of each database is
+// taken from the database config as well.
 type RedisConfig struct {
 	Address  string `env:"REDIS_ADDRESS" env-default:"localhost:6379"`
+	Socket   string `env:"REDIS_SOCKET" env-default:""`
 	Password string `env:"REDIS_PASSWORD" env-default:""`
-	Network  string `env:"REDIS_NETWORK" env-default:"tcp"`
+	// the password read from PasswordFile takes precedence over Password
+	PasswordFile       string `env:"REDIS_PASSWORD_FILE" env-default:""`
+	Network            string `env:"REDIS_NETWORK" env-default:"tcp"`
+	DatabaseConfigPath string `env:"SONIC_DB_CONFIG" env-default:"/var/run/redis/sonic-db/database_config.json"`
+	// database_global.json lists the namespaces of multi-ASIC systems
+	GlobalDatabaseConfigPath string `env:"SONIC_DB_GLOBAL_CONFIG" env-default:"/var/run/redis/sonic-db/database_global.json"`
+	// TLS to redis fronted by e.g. stunnel, the CA and client certificate are optional
+	TLSEnabled            bool   `env:"REDIS_TLS" env-default:"false"`
+	TLSCACert             string `env:"REDIS_TLS_CA_CERT" env-default:""`
+	TLSCert               string `env:"REDIS_TLS_CERT" env-default:""`
+	TLSKey                string `env:"REDIS_TLS_KEY" env-default:""`
+	TLSInsecureSkipVerify bool   `env:"REDIS_TLS_INSECURE_SKIP_VERIFY" env-default:"false"`
+	// Commands failing with transient errors like a refused or dropped connection are
+	// retried up to Retries times, backing off exponentially starting at RetryBackoff.
+	// Error replies of redis are not retried.
+	Retries      int           `env:"REDIS_RETRIES" env-default:"3"`
+	RetryBackoff time.Duration `env:"REDIS_RETRY_BACKOFF" env-default:"8ms"`
 }
 
-func NewClient() (Client, error) {
+func readConfig() (RedisConfig, error) {
 	var cfg RedisConfig
-	c := Client{}
 
 	err := cleanenv.ReadEnv(&cfg)
 	if err != nil {
-		return c, errors.New("failed to read redis config")
+		return cfg, errors.New("failed to read redis config")
+	}
+
+	if cfg.PasswordFile != "" {
+		password, err := os.ReadFile(cfg.PasswordFile)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read redis password file: %w", err)
+		}
+		cfg.Password = strings.TrimRight(string(password), " \t\r\n")
+	}
+
+	// The socket is only used on the unix network, rather than silently
+	// connecting over tcp it implies it or is rejected with another network
+	if cfg.Socket != "" {
+		if _, networkSet := os.LookupEnv("REDIS_NETWORK"); !networkSet {
+			cfg.Network = "unix"
+		} else if cfg.Network != "unix" {
+			return cfg, fmt.Errorf("REDIS_SOCKET requires REDIS_NETWORK unix, got %q", cfg.Network)
+		}
+	}
+
+	return cfg, nil
+}
+
+// NewClient creates a client for the default (host) namespace
+func NewClient() (*Client, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dbConfig, err := LoadDatabaseConfig(cfg.DatabaseConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	_, addressSet := os.LookupEnv("REDIS_ADDRESS")
+	_, socketSet := os.LookupEnv("REDIS_SOCKET")
+
+	return &Client{
+		databases:    make(map[string]*redis.Client),
+		config:       cfg,
+		dbConfig:     dbConfig,
+		useInstances: dbConfig != nil && !addressSet && !socketSet,
+		tlsConfig:    tlsConfig,
+	}, nil
+}
+
+// NewNamespaceClient creates a client for an ASIC namespace. Databases are
+// always reached through the instances of the namespace's database config.
+func NewNamespaceClient(namespace Namespace) (*Client, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dbConfig, err := LoadDatabaseConfig(namespace.DatabaseConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if dbConfig == nil {
+		return nil, fmt.Errorf("database config of namespace %s not found", namespace.Name)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		databases:    make(map[string]*redis.Client),
+		config:       cfg,
+		dbConfig:     dbConfig,
+		useInstances: true,
+		tlsConfig:    tlsConfig,
+	}, nil
+}
+
+// NewTargetClient creates a client for the redis of a remote device listening
+// on a tcp address. Remote devices are read using the default database ids.
+func NewTargetClient(address string) (*Client, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Network = "tcp"
+	cfg.Address = address
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		databases: make(map[string]*redis.Client),
+		config:    cfg,
+		tlsConfig: tlsConfig,
+	}, nil
+}
+
+// Namespaces returns the ASIC namespaces listed in the global database config.
+// Single-ASIC systems have no global database config and no namespaces.
+func Namespaces() ([]Namespace, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadNamespaces(cfg.GlobalDatabaseConfigPath)
+}
+
+// dbId resolves a database name using the database config, falling back to
+// the default SONiC ids
+func (c *Client) dbId(dbName string) (int, bool) {
+	if c.dbConfig != nil {
+		if dbId, ok := c.dbConfig.DbId(dbName); ok {
+			return dbId, true
+		}
+	}
+
+	return RedisDbId(dbName)
+}
+
+func (c *Client) options(dbName string) (*redis.Options, bool) {
+	dbId, ok := c.dbId(dbName)
+	if !ok {
+		return nil, false
+	}
+
+	addr := c.config.Address
+	if c.config.Network == "unix" && c.config.Socket != "" {
+		addr = c.config.Socket
 	}
 
-	c.config = cfg
-	c.databases = make(map[string]*redis.Client)
+	if c.useInstances {
+		if instance, ok := c.dbConfig.Instance(dbName); ok {
+			addr = instance.Address(c.config.Network)
+		}
+	}
 
-	return c, nil
+	return &redis.Options{
+		Network:  c.config.Network,
+		Addr:     addr,
+		Password: c.config.Password,
+		DB:       dbId,
+		// nil unless TLS is enabled
+		TLSConfig: c.tlsConfig,
+		// Bound every command by the deadline of the context it is issued with
+		ContextTimeoutEnabled: true,
+		MaxRetries:            maxRetries(c.config.Retries),
+		MinRetryBackoff:       c.config.RetryBackoff,
+		// don't cap the exponential backoff of up to 10 retries, the context deadline bounds it
+		MaxRetryBackoff: c.config.RetryBackoff << min(max(c.config.Retries, 0), 10),
+	}, true
+}
+
+// maxRetries maps a number of retries to go-redis, which takes -1 to disable
+// retries and 0 for its default
+func maxRetries(retries int) int {
+	if retries <= 0 {
+		return -1
+	}
+
+	return retries
 }
 
 func (c *Client) connect(dbName string) error {
-	dbId, ok := RedisDbId(dbName)
+	options, ok := c.options(dbName)
 	if ok {
-		c.databases[dbName] = redis.NewClient(&redis.Options{
-			Network:  c.config.Network,
-			Addr:     c.config.Address,
-			Password: c.config.Password,
-			DB:       dbId,
-		})
+		client := redis.NewClient(options)
+		client.AddHook(reconnectHook{client: c, dbName: dbName})
+		c.databases[dbName] = client
 		return nil
 	}
 
@@ -67,7 +292,10 @@ func (c *Client) connect(dbName string) error {
 func (c *Client) selectClient(dbName string) (*redis.Client, error) {
 	var client *redis.Client
 
-	_, ok := RedisDbId(dbName)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.dbId(dbName)
 
 	if ok {
 		client, ok = c.databases[dbName]
@@ -87,15 +315,84 @@ func (c *Client) selectClient(dbName string) (*redis.Client, error) {
 	return nil, errors.New("database not defined")
 }
 
-// Issue a HGETALL on key in a selected database
+// SetCommandObserver installs an observer notified after every command, keeping
+// the instrumentation of the client independent of a metrics library
+func (c *Client) SetCommandObserver(observer CommandObserver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.observer = observer
+}
+
+// SetScanKeys selects whether KeysFromDb iterates the keyspace with SCAN, the
+// default, or issues a single KEYS. KEYS is faster on small databases but blocks
+// redis while it walks the whole keyspace.
+func (c *Client) SetScanKeys(scan bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.useKeys = !scan
+}
+
+// observe reports a command started at start to the observer, if any
+func (c *Client) observe(dbName, command string, start time.Time, err error) {
+	c.mu.Lock()
+	observer := c.observer
+	c.mu.Unlock()
+
+	if observer != nil {
+		observer(dbName, command, time.Since(start), err)
+	}
+}
+
+// Issue a HGETALL on key in a selected database, shared with concurrent reads
+// of the key if the read cache is enabled
 func (c *Client) HgetAllFromDb(ctx context.Context, dbName, key string) (map[string]string, error) {
 	client, err := c.selectClient(dbName)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := client.HGetAll(ctx, key).Result()
-	return data, err
+	return c.cachedRead(ctx, dbName, key, func() (map[string]string, error) {
+		start := time.Now()
+		data, err := client.HGetAll(ctx, key).Result()
+		c.observe(dbName, "hgetall", start, err)
+
+		return data, err
+	})
+}
+
+// Issue a HGETALL for each of keys in a selected database using a single
+// pipelined round-trip. The hashes are returned by key.
+func (c *Client) HgetAllPipelined(ctx context.Context, dbName string, keys []string) (map[string]map[string]string, error) {
+	client, err := c.selectClient(dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]map[string]string, len(keys))
+	if len(keys) == 0 {
+		return data, nil
+	}
+
+	pipe := client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.HGetAll(ctx, key)
+	}
+
+	start := time.Now()
+	_, err = pipe.Exec(ctx)
+	c.observe(dbName, "hgetall_pipeline", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, key := range keys {
+		data[key] = cmds[i].Val()
+	}
+
+	return data, nil
 }
 
 func (c *Client) HsetToDb(ctx context.Context, dbName, key string, data map[string]string) error {
@@ -104,23 +401,170 @@ func (c *Client) HsetToDb(ctx context.Context, dbName, key string, data map[stri
 		return err
 	}
 
-	client.HSet(ctx, key, data)
+	start := time.Now()
+	err = client.HSet(ctx, key, data).Err()
+	c.observe(dbName, "hset", start, err)
+
+	return err
+}
+
+// Issue a HDEL of fields of key in a selected database
+func (c *Client) HdelFromDb(ctx context.Context, dbName, key string, fields ...string) error {
+	client, err := c.selectClient(dbName)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = client.HDel(ctx, key, fields...).Err()
+	c.observe(dbName, "hdel", start, err)
+
+	return err
+}
+
+// Issue an EXISTS on key in a selected database
+func (c *Client) ExistsInDb(ctx context.Context, dbName, key string) (bool, error) {
+	client, err := c.selectClient(dbName)
+	if err != nil {
+		return false, err
+	}
+
+	start := time.Now()
+	count, err := client.Exists(ctx, key).Result()
+	c.observe(dbName, "exists", start, err)
+
+	return count > 0, err
+}
+
+// Issue a PUBLISH of message to channel on the redis instance of a selected database
+func (c *Client) PublishToDb(ctx context.Context, dbName, channel, message string) error {
+	client, err := c.selectClient(dbName)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = client.Publish(ctx, channel, message).Err()
+	c.observe(dbName, "publish", start, err)
 
-	return nil
+	return err
 }
 
+// Return the keys matching pattern in a selected database, using SCAN or KEYS
+// as selected by SetScanKeys
 func (c *Client) KeysFromDb(ctx context.Context, dbName, pattern string) ([]string, error) {
+	c.mu.Lock()
+	useKeys := c.useKeys
+	c.mu.Unlock()
+
+	if !useKeys {
+		return c.ScanKeysFromDb(ctx, dbName, pattern)
+	}
+
 	client, err := c.selectClient(dbName)
 	if err != nil {
 		return nil, err
 	}
 
+	start := time.Now()
 	keys, err := client.Keys(ctx, pattern).Result()
+	c.observe(dbName, "keys", start, err)
 
 	return keys, err
 }
 
+// Iterate keys matching pattern in a selected database using SCAN
+func (c *Client) ScanKeysFromDb(ctx context.Context, dbName, pattern string) ([]string, error) {
+	client, err := c.selectClient(dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		keys   []string
+		seen   = make(map[string]struct{})
+		cursor uint64
+	)
+
+	for {
+		start := time.Now()
+		page, nextCursor, err := client.Scan(ctx, cursor, pattern, scanCount).Result()
+		c.observe(dbName, "scan", start, err)
+		if err != nil {
+			return nil, err
+		}
+
+		// SCAN may return a key more than once, keep the result equivalent to KEYS
+		for _, key := range page {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+
+		cursor = nextCursor
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return keys, nil
+}
+
+// Issue a PING on the redis instance of a selected database
+func (c *Client) Ping(ctx context.Context, dbName string) error {
+	client, err := c.selectClient(dbName)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = client.Ping(ctx).Err()
+	c.observe(dbName, "ping", start, err)
+
+	return err
+}
+
+// Issue an INFO for section on the redis instance of a selected database
+func (c *Client) InfoFromDb(ctx context.Context, dbName, section string) (map[string]string, error) {
+	client, err := c.selectClient(dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	info, err := client.Info(ctx, section).Result()
+	c.observe(dbName, "info", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseInfo(info), nil
+}
+
+// parseInfo parses the "field:value" lines of an INFO reply
+func parseInfo(info string) map[string]string {
+	data := make(map[string]string)
+
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		field, value, ok := strings.Cut(line, ":")
+		if ok {
+			data[field] = value
+		}
+	}
+
+	return data
+}
+
 func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for name, client := range c.databases {
 		client.Close()
 		delete(c.databases, name)
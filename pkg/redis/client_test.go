@@ -2,12 +2,28 @@ package redis
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"math/big"
+	"net"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 )
 
 var ctx = context.Background()
@@ -19,7 +35,7 @@ func TestHgetAll(t *testing.T) {
 
 	redisClient, _ := NewClient()
 
-	for _, dbName := range []string{"APPL_DB", "COUNTERS_DB", "CONFIG_DB", "STATE_DB"} {
+	for _, dbName := range []string{"APPL_DB", "ASIC_DB", "COUNTERS_DB", "CONFIG_DB", "STATE_DB"} {
 		var expectedResult = map[string]string{"key1": "value1", "key2": "value2"}
 
 		dbId, _ := RedisDbId(dbName)
@@ -37,3 +53,989 @@ func TestHgetAll(t *testing.T) {
 		}
 	}
 }
+
+func TestScanKeysFromDb(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	dbId, _ := RedisDbId("COUNTERS_DB")
+
+	// Enough keys to require several SCAN iterations
+	expectedKeys := make([]string, 0, 3*scanCount)
+	for i := 0; i < 3*scanCount; i++ {
+		key := fmt.Sprintf("COUNTERS:oid:%d", i)
+		s.DB(dbId).HSet(key, "field", "value")
+		expectedKeys = append(expectedKeys, key)
+	}
+	s.DB(dbId).HSet("COUNTERS_PORT_NAME_MAP", "Ethernet0", "oid:0")
+
+	commandsBefore := s.CommandCount()
+
+	keys, err := redisClient.ScanKeysFromDb(ctx, "COUNTERS_DB", "COUNTERS:*")
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+
+	if iterations := s.CommandCount() - commandsBefore; iterations < 3 {
+		t.Errorf("expected at least 3 SCAN iterations, got %d commands", iterations)
+	}
+
+	sort.Strings(keys)
+	sort.Strings(expectedKeys)
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("scanned keys are not as expected: got %d keys, want %d", len(keys), len(expectedKeys))
+	}
+
+	redisClient.SetScanKeys(false)
+	keysResult, err := redisClient.KeysFromDb(ctx, "COUNTERS_DB", "COUNTERS:*")
+	if err != nil {
+		t.Fatalf("keys failed: %v", err)
+	}
+
+	sort.Strings(keysResult)
+	if !reflect.DeepEqual(keys, keysResult) {
+		t.Errorf("SCAN and KEYS results differ")
+	}
+}
+
+func TestKeysFromDbScan(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	dbId, _ := RedisDbId("STATE_DB")
+	for i := 0; i < 2*scanCount; i++ {
+		s.DB(dbId).HSet(fmt.Sprintf("PSU_INFO|PSU %d", i), "presence", "true")
+	}
+
+	commands := map[string]int{}
+	redisClient.SetCommandObserver(func(dbName, command string, duration time.Duration, err error) {
+		commands[command]++
+	})
+
+	for _, scan := range []bool{true, false} {
+		clear(commands)
+		redisClient.SetScanKeys(scan)
+
+		keys, err := redisClient.KeysFromDb(ctx, "STATE_DB", "PSU_INFO|*")
+		if err != nil {
+			t.Fatalf("scan %v: listing keys failed: %v", scan, err)
+		}
+		if len(keys) != 2*scanCount {
+			t.Errorf("scan %v: got %d keys, want %d", scan, len(keys), 2*scanCount)
+		}
+
+		// SCAN needs several iterations for the keyspace, KEYS a single call
+		if scan && (commands["scan"] < 2 || commands["keys"] != 0) {
+			t.Errorf("expected only SCAN iterations, got %v", commands)
+		}
+		if !scan && (commands["scan"] != 0 || commands["keys"] != 1) {
+			t.Errorf("expected a single KEYS, got %v", commands)
+		}
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       RedisConfig
+		expectedAddr string
+	}{
+		{
+			name:         "tcp uses address",
+			config:       RedisConfig{Network: "tcp", Address: "10.0.0.1:6379", Socket: "/var/run/redis/redis.sock"},
+			expectedAddr: "10.0.0.1:6379",
+		},
+		{
+			name:         "unix prefers socket",
+			config:       RedisConfig{Network: "unix", Address: "localhost:6379", Socket: "/var/run/redis/redis.sock"},
+			expectedAddr: "/var/run/redis/redis.sock",
+		},
+		{
+			name:         "unix falls back to address",
+			config:       RedisConfig{Network: "unix", Address: "/var/run/redis/redis.sock"},
+			expectedAddr: "/var/run/redis/redis.sock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Client{config: tt.config}
+
+			options, _ := c.options("STATE_DB")
+
+			if options.Network != tt.config.Network {
+				t.Errorf("unexpected network: got %q, want %q", options.Network, tt.config.Network)
+			}
+			if options.Addr != tt.expectedAddr {
+				t.Errorf("unexpected address: got %q, want %q", options.Addr, tt.expectedAddr)
+			}
+			if options.DB != 6 {
+				t.Errorf("unexpected db: got %d, want 6", options.DB)
+			}
+		})
+	}
+}
+
+func TestDatabaseConfig(t *testing.T) {
+	dbConfig, err := LoadDatabaseConfig("../../fixtures/test/database_config.json")
+	if err != nil {
+		t.Fatalf("failed to load database config: %v", err)
+	}
+
+	c := Client{config: RedisConfig{Network: "tcp"}, dbConfig: dbConfig, useInstances: true}
+
+	tests := []struct {
+		dbName       string
+		expectedId   int
+		expectedAddr string
+	}{
+		{dbName: "APPL_DB", expectedId: 0, expectedAddr: "127.0.0.1:6379"},
+		{dbName: "ASIC_DB", expectedId: 1, expectedAddr: "127.0.0.1:6379"},
+		{dbName: "STATE_DB", expectedId: 6, expectedAddr: "127.0.0.1:6379"},
+		{dbName: "CHASSIS_APP_DB", expectedId: 12, expectedAddr: "10.0.0.16:6380"},
+	}
+
+	for _, tt := range tests {
+		options, ok := c.options(tt.dbName)
+		if !ok {
+			t.Errorf("%s: database not resolved", tt.dbName)
+			continue
+		}
+		if options.DB != tt.expectedId {
+			t.Errorf("%s: unexpected db id: got %d, want %d", tt.dbName, options.DB, tt.expectedId)
+		}
+		if options.Addr != tt.expectedAddr {
+			t.Errorf("%s: unexpected address: got %q, want %q", tt.dbName, options.Addr, tt.expectedAddr)
+		}
+	}
+
+	c.config.Network = "unix"
+	options, _ := c.options("CHASSIS_APP_DB")
+	if options.Addr != "/var/run/redis-chassis/redis_chassis.sock" {
+		t.Errorf("unexpected unix socket address: %q", options.Addr)
+	}
+
+	if _, ok := c.options("UNKNOWN_DB"); ok {
+		t.Errorf("unknown database should not resolve")
+	}
+}
+
+func TestDatabaseConfigMissing(t *testing.T) {
+	dbConfig, err := LoadDatabaseConfig(t.TempDir() + "/database_config.json")
+	if err != nil {
+		t.Fatalf("missing database config should not be an error: %v", err)
+	}
+	if dbConfig != nil {
+		t.Fatalf("expected no database config")
+	}
+
+	c := Client{config: RedisConfig{Network: "tcp", Address: "localhost:6379"}}
+
+	for _, dbName := range []string{"APPL_DB", "COUNTERS_DB", "CONFIG_DB", "STATE_DB"} {
+		expectedId, _ := RedisDbId(dbName)
+
+		options, ok := c.options(dbName)
+		if !ok {
+			t.Fatalf("%s: database not resolved", dbName)
+		}
+		if options.DB != expectedId || options.Addr != "localhost:6379" {
+			t.Errorf("%s: unexpected options: db %d, address %q", dbName, options.DB, options.Addr)
+		}
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	namespaces, err := LoadNamespaces("../../fixtures/test/multi_asic/redis/sonic-db/database_global.json")
+	if err != nil {
+		t.Fatalf("failed to load namespaces: %v", err)
+	}
+
+	expectedAddrs := map[string]string{
+		"asic0": "127.0.0.1:6380",
+		"asic1": "127.0.0.1:6381",
+	}
+
+	if len(namespaces) != len(expectedAddrs) {
+		t.Fatalf("unexpected namespaces: %v", namespaces)
+	}
+
+	for _, namespace := range namespaces {
+		namespaceClient, err := NewNamespaceClient(namespace)
+		if err != nil {
+			t.Fatalf("%s: failed to create client: %v", namespace.Name, err)
+		}
+
+		options, _ := namespaceClient.options("COUNTERS_DB")
+		if options.Addr != expectedAddrs[namespace.Name] {
+			t.Errorf("%s: unexpected address: got %q, want %q", namespace.Name, options.Addr, expectedAddrs[namespace.Name])
+		}
+	}
+}
+
+func TestNamespacesSingleAsic(t *testing.T) {
+	namespaces, err := LoadNamespaces(t.TempDir() + "/database_global.json")
+	if err != nil {
+		t.Fatalf("missing global database config should not be an error: %v", err)
+	}
+
+	if len(namespaces) != 0 {
+		t.Errorf("expected no namespaces on single-ASIC system, got %v", namespaces)
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	info := "# Server\r\nredis_version:6.0.16\r\nredis_mode:standalone\r\nuptime_in_seconds:86400\r\nexecutable:/usr/bin/redis-server\r\n\r\n"
+
+	expectedResult := map[string]string{
+		"redis_version":     "6.0.16",
+		"redis_mode":        "standalone",
+		"uptime_in_seconds": "86400",
+		"executable":        "/usr/bin/redis-server",
+	}
+
+	if result := parseInfo(info); !reflect.DeepEqual(result, expectedResult) {
+		t.Errorf("parsed info is not as expected: %v", result)
+	}
+}
+
+func TestRedisDbId(t *testing.T) {
+	expected := map[string]int{
+		"APPL_DB":            0,
+		"ASIC_DB":            1,
+		"COUNTERS_DB":        2,
+		"LOGLEVEL_DB":        3,
+		"CONFIG_DB":          4,
+		"PFC_WD_DB":          5,
+		"FLEX_COUNTER_DB":    5,
+		"STATE_DB":           6,
+		"SNMP_OVERLAY_DB":    7,
+		"RESTAPI_DB":         8,
+		"GB_ASIC_DB":         9,
+		"GB_COUNTERS_DB":     10,
+		"GB_FLEX_COUNTER_DB": 11,
+		"CHASSIS_APP_DB":     12,
+		"CHASSIS_STATE_DB":   13,
+		"APPL_STATE_DB":      14,
+	}
+
+	for name, expectedId := range expected {
+		dbId, ok := RedisDbId(name)
+		if !ok || dbId != expectedId {
+			t.Errorf("%s resolved to %d, %v, want %d", name, dbId, ok, expectedId)
+		}
+	}
+
+	if _, ok := RedisDbId("UNKNOWN_DB"); ok {
+		t.Errorf("unknown database should not resolve")
+	}
+}
+
+func TestCommandObserver(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	type observation struct {
+		dbName  string
+		command string
+		failed  bool
+	}
+
+	var observations []observation
+	redisClient.SetCommandObserver(func(dbName, command string, duration time.Duration, err error) {
+		if duration < 0 {
+			t.Errorf("negative duration for %s %s", dbName, command)
+		}
+		observations = append(observations, observation{dbName: dbName, command: command, failed: err != nil})
+	})
+
+	if err := redisClient.HsetToDb(ctx, "CONFIG_DB", "hash1", map[string]string{"key1": "value1"}); err != nil {
+		t.Fatalf("hset failed: %v", err)
+	}
+	if _, err := redisClient.HgetAllFromDb(ctx, "CONFIG_DB", "hash1"); err != nil {
+		t.Fatalf("hgetall failed: %v", err)
+	}
+	if _, err := redisClient.KeysFromDb(ctx, "STATE_DB", "*"); err != nil {
+		t.Fatalf("keys failed: %v", err)
+	}
+
+	s.SetError("server is unavailable")
+	if _, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "hash1"); err == nil {
+		t.Fatalf("hgetall should fail while redis returns errors")
+	}
+	if _, err := redisClient.ScanKeysFromDb(ctx, "COUNTERS_DB", "*"); err == nil {
+		t.Fatalf("scan should fail while redis returns errors")
+	}
+
+	expected := []observation{
+		{dbName: "CONFIG_DB", command: "hset"},
+		{dbName: "CONFIG_DB", command: "hgetall"},
+		{dbName: "STATE_DB", command: "scan"},
+		{dbName: "STATE_DB", command: "hgetall", failed: true},
+		{dbName: "COUNTERS_DB", command: "scan", failed: true},
+	}
+
+	if !reflect.DeepEqual(observations, expected) {
+		t.Errorf("unexpected observations:\ngot  %v\nwant %v", observations, expected)
+	}
+}
+
+func TestPing(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	if err := redisClient.Ping(ctx, "STATE_DB"); err != nil {
+		t.Errorf("ping failed while redis is up: %v", err)
+	}
+
+	if err := redisClient.Ping(ctx, "UNKNOWN_DB"); err == nil {
+		t.Errorf("ping of an unknown database should fail")
+	}
+
+	s.Close()
+
+	if err := redisClient.Ping(ctx, "STATE_DB"); err == nil {
+		t.Errorf("ping should fail while redis is down")
+	}
+}
+
+func TestHsetToDbError(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	// HSET on a string key is refused with WRONGTYPE
+	dbId, _ := RedisDbId("CONFIG_DB")
+	s.DB(dbId).Set("string1", "value1")
+
+	err := redisClient.HsetToDb(ctx, "CONFIG_DB", "string1", map[string]string{"key1": "value1"})
+	if err == nil || !strings.Contains(err.Error(), "WRONGTYPE") {
+		t.Errorf("expected the WRONGTYPE error of redis, got %v", err)
+	}
+
+	s.SetError("server unavailable")
+	defer s.SetError("")
+
+	err = redisClient.HsetToDb(ctx, "CONFIG_DB", "hash1", map[string]string{"key1": "value1"})
+	if err == nil || !strings.Contains(err.Error(), "server unavailable") {
+		t.Errorf("expected the error of redis, got %v", err)
+	}
+}
+
+func TestHdelAndExists(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	if err := redisClient.HsetToDb(ctx, "STATE_DB", "hash1", map[string]string{"key1": "value1", "key2": "value2"}); err != nil {
+		t.Fatalf("hset failed: %v", err)
+	}
+
+	exists, err := redisClient.ExistsInDb(ctx, "STATE_DB", "hash1")
+	if err != nil || !exists {
+		t.Errorf("hash1 should exist, got %v, %v", exists, err)
+	}
+
+	if err := redisClient.HdelFromDb(ctx, "STATE_DB", "hash1", "key1"); err != nil {
+		t.Errorf("hdel failed: %v", err)
+	}
+
+	result, _ := redisClient.HgetAllFromDb(ctx, "STATE_DB", "hash1")
+	if !reflect.DeepEqual(result, map[string]string{"key2": "value2"}) {
+		t.Errorf("unexpected hash after hdel: %v", result)
+	}
+
+	// deleting the last field removes the key
+	if err := redisClient.HdelFromDb(ctx, "STATE_DB", "hash1", "key2"); err != nil {
+		t.Errorf("hdel failed: %v", err)
+	}
+
+	exists, err = redisClient.ExistsInDb(ctx, "STATE_DB", "hash1")
+	if err != nil || exists {
+		t.Errorf("hash1 should not exist, got %v, %v", exists, err)
+	}
+
+	if _, err := redisClient.ExistsInDb(ctx, "UNKNOWN_DB", "hash1"); err == nil {
+		t.Errorf("exists in an unknown database should fail")
+	}
+
+	if err := redisClient.HdelFromDb(ctx, "UNKNOWN_DB", "hash1", "key1"); err == nil {
+		t.Errorf("hdel in an unknown database should fail")
+	}
+}
+
+func TestPublishToDb(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	subscriber := s.NewSubscriber()
+	defer subscriber.Close()
+	subscriber.Subscribe("channel1")
+
+	received := make(chan miniredis.PubsubMessage, 1)
+	go func() {
+		received <- <-subscriber.Messages()
+	}()
+
+	if err := redisClient.PublishToDb(ctx, "APPL_DB", "channel1", "message1"); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+
+	select {
+	case message := <-received:
+		if message.Message != "message1" {
+			t.Errorf("unexpected message: %v", message)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("message was not published")
+	}
+
+	if err := redisClient.PublishToDb(ctx, "UNKNOWN_DB", "channel1", "message1"); err == nil {
+		t.Errorf("publish in an unknown database should fail")
+	}
+}
+
+// flakyProxy forwards connections to addr after dropping the first drops connections
+type flakyProxy struct {
+	listener net.Listener
+	accepted atomic.Int32
+}
+
+func newFlakyProxy(t *testing.T, addr string, drops int32) *flakyProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	proxy := &flakyProxy{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if proxy.accepted.Add(1) <= drops {
+				conn.Close()
+				continue
+			}
+
+			upstream, err := net.Dial("tcp", addr)
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			go func() {
+				io.Copy(upstream, conn)
+				upstream.Close()
+			}()
+			go func() {
+				io.Copy(conn, upstream)
+				conn.Close()
+			}()
+		}
+	}()
+
+	return proxy
+}
+
+func TestRetries(t *testing.T) {
+	s := miniredis.RunT(t)
+	s.DB(6).HSet("hash1", "key1", "value1")
+
+	tests := []struct {
+		name     string
+		retries  int
+		drops    int32
+		succeeds bool
+	}{
+		{name: "succeeds after two dropped connections", retries: 3, drops: 2, succeeds: true},
+		{name: "gives up after the retries", retries: 1, drops: 2, succeeds: false},
+		{name: "retries disabled", retries: 0, drops: 1, succeeds: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy := newFlakyProxy(t, s.Addr(), tt.drops)
+
+			redisClient := &Client{
+				databases: make(map[string]*redis.Client),
+				config: RedisConfig{
+					Network:      "tcp",
+					Address:      proxy.listener.Addr().String(),
+					Retries:      tt.retries,
+					RetryBackoff: time.Millisecond,
+				},
+			}
+			defer redisClient.Close()
+
+			data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "hash1")
+			if tt.succeeds && (err != nil || data["key1"] != "value1") {
+				t.Errorf("expected the read to succeed, got %v, %v", data, err)
+			}
+			if !tt.succeeds && err == nil {
+				t.Errorf("expected the read to fail")
+			}
+
+			if attempts := proxy.accepted.Load(); attempts != int32(min(tt.retries, int(tt.drops)))+1 {
+				t.Errorf("unexpected number of attempts: %d", attempts)
+			}
+		})
+	}
+
+	// error replies are not retried
+	proxy := newFlakyProxy(t, s.Addr(), 0)
+	redisClient := &Client{
+		databases: make(map[string]*redis.Client),
+		config:    RedisConfig{Network: "tcp", Address: proxy.listener.Addr().String(), Retries: 3, RetryBackoff: time.Millisecond},
+	}
+	defer redisClient.Close()
+
+	s.DB(6).Set("string1", "value1")
+	// connect before counting commands
+	if _, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "hash1"); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	commands := s.CommandCount()
+	if _, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "string1"); err == nil {
+		t.Errorf("expected the WRONGTYPE error of redis")
+	}
+	if issued := s.CommandCount() - commands; issued != 1 {
+		t.Errorf("expected the failed command to be issued once, %d commands issued", issued)
+	}
+}
+
+func TestReconnectObserver(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	var (
+		mu         sync.Mutex
+		reconnects []string
+	)
+	redisClient.SetReconnectObserver(func(dbName string) {
+		mu.Lock()
+		defer mu.Unlock()
+		reconnects = append(reconnects, dbName)
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := redisClient.Ping(ctx, "STATE_DB"); err != nil {
+			t.Fatalf("ping failed: %v", err)
+		}
+	}
+
+	mu.Lock()
+	if len(reconnects) != 0 {
+		t.Errorf("the initial connection is no reconnect: %v", reconnects)
+	}
+	mu.Unlock()
+
+	// a restart drops the connection, the next command reconnects
+	s.Close()
+	if err := s.Restart(); err != nil {
+		t.Fatalf("restart failed: %v", err)
+	}
+	if err := redisClient.Ping(ctx, "STATE_DB"); err != nil {
+		t.Fatalf("ping after restart failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(reconnects, []string{"STATE_DB"}) {
+		t.Errorf("expected a reconnect of STATE_DB, got %v", reconnects)
+	}
+}
+
+// writeTestCertificate writes a self-signed certificate and its key to dir
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certPath := filepath.Join(dir, "redis.crt")
+	keyPath := filepath.Join(dir, "redis.key")
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0o600)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600)
+
+	return certPath, keyPath
+}
+
+func TestTLSConfig(t *testing.T) {
+	certPath, keyPath := writeTestCertificate(t, t.TempDir())
+
+	tlsConfig, err := newTLSConfig(RedisConfig{TLSEnabled: true, TLSCACert: certPath, TLSCert: certPath, TLSKey: keyPath})
+	if err != nil {
+		t.Fatalf("failed to build TLS config: %v", err)
+	}
+
+	c := Client{config: RedisConfig{Network: "tcp", Address: "localhost:6379"}, tlsConfig: tlsConfig}
+
+	options, _ := c.options("STATE_DB")
+	if options.TLSConfig == nil {
+		t.Fatalf("TLS enabled but redis options have no TLS config")
+	}
+	if options.TLSConfig.RootCAs == nil || len(options.TLSConfig.Certificates) != 1 {
+		t.Errorf("CA or client certificate not loaded")
+	}
+
+	tlsConfig, err = newTLSConfig(RedisConfig{})
+	if err != nil || tlsConfig != nil {
+		t.Errorf("TLS disabled should build no TLS config: %v, %v", tlsConfig, err)
+	}
+
+	errorTests := []struct {
+		name   string
+		config RedisConfig
+		reason string
+	}{
+		{
+			name:   "certificate without key",
+			config: RedisConfig{TLSEnabled: true, TLSCert: certPath},
+			reason: "must be set together",
+		},
+		{
+			name:   "missing CA certificate",
+			config: RedisConfig{TLSEnabled: true, TLSCACert: "/nonexistent/ca.crt"},
+			reason: "failed to read redis TLS CA certificate",
+		},
+		{
+			name:   "invalid CA certificate",
+			config: RedisConfig{TLSEnabled: true, TLSCACert: keyPath},
+			reason: "no certificates found",
+		},
+		{
+			name:   "missing client certificate",
+			config: RedisConfig{TLSEnabled: true, TLSCert: "/nonexistent/client.crt", TLSKey: keyPath},
+			reason: "failed to load redis TLS client certificate",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTLSConfig(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("got error %v, want %q", err, tt.reason)
+			}
+		})
+	}
+}
+
+func TestHgetAllPipelined(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	dbId, _ := RedisDbId("STATE_DB")
+
+	keys := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("FAN_INFO|fan%d", i)
+		s.DB(dbId).HSet(key, "speed", fmt.Sprint(i*100), "status", "True")
+		keys = append(keys, key)
+	}
+	// missing keys read as empty hashes, as with HGETALL
+	keys = append(keys, "FAN_INFO|missing")
+
+	// connection setup issues commands of its own
+	redisClient.Ping(ctx, "STATE_DB")
+	commandsBefore := s.CommandCount()
+
+	result, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", keys)
+	if err != nil {
+		t.Fatalf("pipelined hgetall failed: %v", err)
+	}
+
+	if pipelined := s.CommandCount() - commandsBefore; pipelined != len(keys) {
+		t.Errorf("unexpected number of commands: got %d, want %d", pipelined, len(keys))
+	}
+
+	for _, key := range keys {
+		expected, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", key)
+		if err != nil {
+			t.Fatalf("hgetall failed: %v", err)
+		}
+		if !reflect.DeepEqual(result[key], expected) {
+			t.Errorf("%s: pipelined result %v differs from %v", key, result[key], expected)
+		}
+	}
+
+	if result, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", nil); err != nil || len(result) != 0 {
+		t.Errorf("no keys should read nothing: %v, %v", result, err)
+	}
+
+	s.DB(dbId).Set("FAN_INFO|string", "value")
+	if _, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", append(keys, "FAN_INFO|string")); err == nil {
+		t.Errorf("reading a non-hash key should fail")
+	}
+}
+
+func BenchmarkHgetAll(b *testing.B) {
+	s := miniredis.RunT(b)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	dbId, _ := RedisDbId("STATE_DB")
+
+	keys := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("FAN_INFO|fan%d", i)
+		s.DB(dbId).HSet(key, "speed", "5000", "status", "True")
+		keys = append(keys, key)
+	}
+
+	b.Run("per-key", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, key := range keys {
+				if _, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", key); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("pipelined", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := redisClient.HgetAllPipelined(ctx, "STATE_DB", keys); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestPasswordFile(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "redis_password")
+	os.WriteFile(passwordFile, []byte("file-secret\n"), 0o600)
+
+	tests := []struct {
+		name             string
+		password         string
+		passwordFile     string
+		expectedPassword string
+		expectError      bool
+	}{
+		{
+			name:             "password from environment",
+			password:         "env-secret",
+			expectedPassword: "env-secret",
+		},
+		{
+			name:             "password from file",
+			passwordFile:     passwordFile,
+			expectedPassword: "file-secret",
+		},
+		{
+			name:             "file takes precedence",
+			password:         "env-secret",
+			passwordFile:     passwordFile,
+			expectedPassword: "file-secret",
+		},
+		{
+			name:         "missing file",
+			passwordFile: filepath.Join(t.TempDir(), "missing"),
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("REDIS_PASSWORD", tt.password)
+			t.Setenv("REDIS_PASSWORD_FILE", tt.passwordFile)
+
+			cfg, err := readConfig()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected an error for a missing password file")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to read config: %v", err)
+			}
+
+			if cfg.Password != tt.expectedPassword {
+				t.Errorf("got password %q, want %q", cfg.Password, tt.expectedPassword)
+			}
+		})
+	}
+}
+
+func TestReadCache(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	os.Setenv("REDIS_ADDRESS", s.Addr())
+
+	redisClient, _ := NewClient()
+	defer redisClient.Close()
+
+	dbId, _ := RedisDbId("STATE_DB")
+	s.DB(dbId).HSet("PSU_INFO|PSU 1", "presence", "true")
+
+	var reads atomic.Int32
+	redisClient.SetCommandObserver(func(dbName, command string, duration time.Duration, err error) {
+		if command == "hgetall" {
+			reads.Add(1)
+		}
+	})
+
+	// readConcurrently reads the PSU hash from several goroutines at once
+	readConcurrently := func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "PSU_INFO|PSU 1")
+				if err != nil || data["presence"] != "true" {
+					t.Errorf("unexpected read result: %v, %v", data, err)
+				}
+				// readers own their copy
+				data["presence"] = "false"
+			}()
+		}
+		wg.Wait()
+	}
+
+	readConcurrently()
+	if n := reads.Swap(0); n != 4 {
+		t.Errorf("without read cache expected 4 round-trips, got %d", n)
+	}
+
+	redisClient.SetReadCache(time.Hour)
+	readConcurrently()
+	readConcurrently()
+	if n := reads.Swap(0); n != 1 {
+		t.Errorf("readers within the window should share one round-trip, got %d", n)
+	}
+
+	if _, err := redisClient.HgetAllFromDb(ctx, "CONFIG_DB", "PSU_INFO|PSU 1"); err != nil {
+		t.Fatalf("hgetall failed: %v", err)
+	}
+	if n := reads.Swap(0); n != 1 {
+		t.Errorf("the same key of another database should not be shared, got %d round-trips", n)
+	}
+
+	// Failed reads are not shared with later readers
+	s.SetError("LOADING redis is loading the dataset in memory")
+	if _, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "PSU_INFO|PSU 2"); err == nil {
+		t.Fatalf("hgetall should fail while redis returns errors")
+	}
+	s.SetError("")
+	if _, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "PSU_INFO|PSU 2"); err != nil {
+		t.Errorf("read after a failed read should reach redis: %v", err)
+	}
+	reads.Store(0)
+
+	// A later epoch reads fresh data
+	redisClient.SetReadCache(50 * time.Millisecond)
+	if _, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "PSU_INFO|PSU 1"); err != nil {
+		t.Fatalf("hgetall failed: %v", err)
+	}
+	s.DB(dbId).HSet("PSU_INFO|PSU 1", "presence", "false")
+	time.Sleep(100 * time.Millisecond)
+
+	data, err := redisClient.HgetAllFromDb(ctx, "STATE_DB", "PSU_INFO|PSU 1")
+	if err != nil || data["presence"] != "false" {
+		t.Errorf("expected the data of the new epoch, got %v, %v", data, err)
+	}
+	if n := reads.Load(); n != 2 {
+		t.Errorf("expected a round-trip per epoch, got %d", n)
+	}
+}
+
+func TestReadConfigSocket(t *testing.T) {
+	tests := []struct {
+		name            string
+		socket          string
+		network         string
+		networkSet      bool
+		expectedNetwork string
+		expectError     bool
+	}{
+		{name: "tcp by default", expectedNetwork: "tcp"},
+		{name: "socket implies unix", socket: "/var/run/redis/redis.sock", expectedNetwork: "unix"},
+		{name: "socket with unix", socket: "/var/run/redis/redis.sock", network: "unix", networkSet: true, expectedNetwork: "unix"},
+		{name: "socket with tcp", socket: "/var/run/redis/redis.sock", network: "tcp", networkSet: true, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("REDIS_SOCKET", tt.socket)
+			t.Setenv("REDIS_NETWORK", tt.network)
+			if !tt.networkSet {
+				os.Unsetenv("REDIS_NETWORK")
+			}
+
+			cfg, err := readConfig()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected an error for a socket on network %q", tt.network)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to read config: %v", err)
+			}
+
+			if cfg.Network != tt.expectedNetwork {
+				t.Errorf("got network %q, want %q", cfg.Network, tt.expectedNetwork)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// DatabaseInstance describes a redis instance from SONiC's database_config.json
+type DatabaseInstance struct {
+	Hostname       string `json:"hostname"`
+	Port           int    `json:"port"`
+	UnixSocketPath string `json:"unix_socket_path"`
+}
+
+// Database describes a logical database from SONiC's database_config.json
+type Database struct {
+	Id        int    `json:"id"`
+	Separator string `json:"separator"`
+	Instance  string `json:"instance"`
+}
+
+// DatabaseConfig is the content of SONiC's database_config.json, which maps
+// database names to their numeric ids and the redis instance serving them.
+type DatabaseConfig struct {
+	Instances map[string]DatabaseInstance `json:"INSTANCES"`
+	Databases map[string]Database         `json:"DATABASES"`
+}
+
+// LoadDatabaseConfig reads a database_config.json file. A missing file is not
+// an error, nil is returned and callers fall back to the default database ids.
+func LoadDatabaseConfig(path string) (*DatabaseConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database config: %w", err)
+	}
+
+	var config DatabaseConfig
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database config %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// DbId returns the id of a database, the bool is false for unknown databases
+func (d *DatabaseConfig) DbId(name string) (int, bool) {
+	database, ok := d.Databases[name]
+	if !ok {
+		return 0, false
+	}
+
+	return database.Id, true
+}
+
+// Instance returns the redis instance serving a database
+func (d *DatabaseConfig) Instance(name string) (DatabaseInstance, bool) {
+	database, ok := d.Databases[name]
+	if !ok {
+		return DatabaseInstance{}, false
+	}
+
+	instance, ok := d.Instances[database.Instance]
+	return instance, ok
+}
+
+// Address returns the address of the instance for the given network type
+func (i DatabaseInstance) Address(network string) string {
+	if network == "unix" {
+		return i.UnixSocketPath
+	}
+
+	return net.JoinHostPort(i.Hostname, strconv.Itoa(i.Port))
+}
+
+// Namespace is a redis namespace of a multi-ASIC system, e.g. asic0
+type Namespace struct {
+	Name               string
+	DatabaseConfigPath string
+}
+
+// globalDatabaseConfig is the content of SONiC's database_global.json
+type globalDatabaseConfig struct {
+	Includes []struct {
+		Namespace string `json:"namespace"`
+		Include   string `json:"include"`
+	} `json:"INCLUDES"`
+}
+
+// LoadNamespaces reads a database_global.json file and returns the ASIC
+// namespaces it includes, sorted by name. The host entry, which has no
+// namespace, is skipped. A missing file is not an error, nil is returned.
+func LoadNamespaces(path string) ([]Namespace, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global database config: %w", err)
+	}
+
+	var config globalDatabaseConfig
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse global database config %s: %w", path, err)
+	}
+
+	var namespaces []Namespace
+	for _, include := range config.Includes {
+		if include.Namespace == "" {
+			continue
+		}
+
+		// include paths are relative to the global database config
+		includePath := include.Include
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(path), includePath)
+		}
+
+		namespaces = append(namespaces, Namespace{Name: include.Namespace, DatabaseConfigPath: includePath})
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+
+	return namespaces, nil
+}
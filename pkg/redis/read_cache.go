@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"maps"
+	"time"
+)
+
+// readCacheKey identifies a cached hash
+type readCacheKey struct {
+	dbName string
+	key    string
+}
+
+// readCacheEntry is a hash read, or being read, in the current epoch. done is
+// closed once data and err are set.
+type readCacheEntry struct {
+	done chan struct{}
+	data map[string]string
+	err  error
+}
+
+// SetReadCache lets HGETALLs of the same key within ttl share a single redis
+// round-trip, e.g. when several collectors scraped at once read the same hash.
+// Time is split into epochs of ttl and results are only shared within an epoch,
+// so a later scrape never reads data of an earlier one. 0 disables the cache.
+func (c *Client) SetReadCache(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readCacheTTL = ttl
+	c.readCache = nil
+}
+
+// cachedRead returns the hash of key in a database read by fetch, sharing the
+// result with other reads of the key in the current epoch. Failed reads are
+// not shared with later reads.
+func (c *Client) cachedRead(ctx context.Context, dbName, key string, fetch func() (map[string]string, error)) (map[string]string, error) {
+	c.mu.Lock()
+	if c.readCacheTTL <= 0 {
+		c.mu.Unlock()
+		return fetch()
+	}
+
+	epoch := time.Now().UnixNano() / int64(c.readCacheTTL)
+	if c.readCache == nil || c.readCacheEpoch != epoch {
+		c.readCache = make(map[readCacheKey]*readCacheEntry)
+		c.readCacheEpoch = epoch
+	}
+
+	cacheKey := readCacheKey{dbName: dbName, key: key}
+	if entry, ok := c.readCache[cacheKey]; ok {
+		c.mu.Unlock()
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		// callers own the returned map
+		return maps.Clone(entry.data), nil
+	}
+
+	entry := &readCacheEntry{done: make(chan struct{})}
+	c.readCache[cacheKey] = entry
+	c.mu.Unlock()
+
+	entry.data, entry.err = fetch()
+	if entry.err != nil {
+		c.mu.Lock()
+		if c.readCache[cacheKey] == entry {
+			delete(c.readCache, cacheKey)
+		}
+		c.mu.Unlock()
+	}
+	close(entry.done)
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return maps.Clone(entry.data), nil
+}
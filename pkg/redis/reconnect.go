@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReconnectObserver is notified whenever the client re-establishes a connection
+// to a database after a connection to it broke, e.g. when redis restarted
+type ReconnectObserver func(dbName string)
+
+// reconnectHook tracks the connections of a database. go-redis replaces broken
+// connections transparently, the hook reports every new connection replacing
+// one that failed with a read or write error as reconnect.
+type reconnectHook struct {
+	client *Client
+	dbName string
+}
+
+func (h reconnectHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		h.client.connected(h.dbName)
+		return &trackedConn{Conn: conn, broken: func() { h.client.broken(h.dbName) }}, nil
+	}
+}
+
+func (h reconnectHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return next
+}
+
+func (h reconnectHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+// trackedConn calls broken once when a read or write fails
+type trackedConn struct {
+	net.Conn
+	broken func()
+	once   sync.Once
+}
+
+func (conn *trackedConn) Read(b []byte) (int, error) {
+	n, err := conn.Conn.Read(b)
+	if err != nil {
+		conn.once.Do(conn.broken)
+	}
+	return n, err
+}
+
+func (conn *trackedConn) Write(b []byte) (int, error) {
+	n, err := conn.Conn.Write(b)
+	if err != nil {
+		conn.once.Do(conn.broken)
+	}
+	return n, err
+}
+
+// SetReconnectObserver installs an observer notified about reconnects to a database
+func (c *Client) SetReconnectObserver(observer ReconnectObserver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reconnectObserver = observer
+}
+
+// broken records a broken connection of a database
+func (c *Client) broken(dbName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.brokenConns == nil {
+		c.brokenConns = make(map[string]int)
+	}
+	c.brokenConns[dbName]++
+}
+
+// connected records a new connection of a database, reporting it as reconnect
+// if it replaces a broken one
+func (c *Client) connected(dbName string) {
+	c.mu.Lock()
+	reconnect := c.brokenConns[dbName] > 0
+	if reconnect {
+		c.brokenConns[dbName]--
+	}
+	observer := c.reconnectObserver
+	c.mu.Unlock()
+
+	if reconnect && observer != nil {
+		observer(dbName)
+	}
+}